@@ -1,6 +1,10 @@
 package config
 
-import "time"
+import (
+	"crypto/tls"
+	"runtime"
+	"time"
+)
 
 // Config holds all configuration for internet tests
 type Config struct {
@@ -9,6 +13,229 @@ type Config struct {
 	PingTimeout      time.Duration
 	SpeedTestTimeout time.Duration
 	ResultsFilePath  string
+
+	// ResultsFormat selects the results writer: "json", "csv", "html" or "influx"
+	ResultsFormat string
+
+	// RequestsPerSecond limits how many HTTP tests may start per second (0 = unlimited)
+	RequestsPerSecond float64
+
+	// CaptureResponseHeaders stores response headers in HTTP test results
+	CaptureResponseHeaders bool
+
+	// DNSResolver is the "host:port" of the DNS server to query (empty = system resolver)
+	DNSResolver string
+
+	// ThrottleTestGap is the pause between the two downloads of a throttle test
+	ThrottleTestGap time.Duration
+
+	// ThrottleThresholdPct is the slowdown percentage above which throttling is reported
+	ThrottleThresholdPct float64
+
+	// LocalInterface binds outgoing connections to the named network interface (empty = OS default)
+	LocalInterface string
+
+	// RegressionTolerance is the percentage a metric may worsen before diff mode reports a regression
+	RegressionTolerance float64
+
+	// TLSMinVersion is the minimum TLS version accepted by HTTP tests (a tls.Version* constant)
+	TLSMinVersion uint16
+
+	// TLSMaxVersion is the maximum TLS version accepted by HTTP tests (0 = library default)
+	TLSMaxVersion uint16
+
+	// VPNCheckEnabled runs the VPN/proxy detection test, which contacts an external IP service
+	VPNCheckEnabled bool
+
+	// SpeedCheckEnabled runs the download speed tests
+	SpeedCheckEnabled bool
+
+	// PingCheckEnabled runs the ICMP ping tests
+	PingCheckEnabled bool
+
+	// DNSTestEnabled resolves the host of every HTTP test URL as a separate DNS test
+	DNSTestEnabled bool
+
+	// WorkerCount bounds how many lookups or connections scanning tests run at once
+	WorkerCount int
+
+	// SubdomainWordlistPath is a file of subdomain labels, one per line, used by subdomain enumeration
+	SubdomainWordlistPath string
+
+	// MaxRedirects is how many redirects HTTP tests follow (0 = report the first response)
+	MaxRedirects int
+
+	// PIDFilePath is where --daemon writes the background process ID
+	PIDFilePath string
+
+	// LogFilePath receives stdout and stderr of the background process started by --daemon
+	LogFilePath string
+
+	// HTTPConnectTimeout bounds how long establishing a single TCP connection may take
+	HTTPConnectTimeout time.Duration
+
+	// BGPAPIEndpoint is the RIPE Stat looking glass URL queried by BGP route checks
+	BGPAPIEndpoint string
+
+	// WatchInterval repeats the default tests at this interval (0 = run once)
+	WatchInterval time.Duration
+
+	// WatchJitter adds a random delay of up to this duration to every watch interval
+	WatchJitter time.Duration
+
+	// Debug enables debug logging
+	Debug bool
+
+	// UserAgent is sent with every HTTP request made by the tests
+	UserAgent string
+
+	// EnableGeolocation looks up the location of the external IP found by the VPN check
+	EnableGeolocation bool
+
+	// GeolocationAPI is the IP geolocation URL; "{ip}" is replaced by the address to look up
+	GeolocationAPI string
+
+	// RepeatCount is how many times each HTTP and speed test runs
+	RepeatCount int
+
+	// RepeatDelay is the pause between repeats of the same test
+	RepeatDelay time.Duration
+
+	// TestCaching revalidates every HTTP test response with a conditional request to detect caching
+	TestCaching bool
+
+	// DNSCacheEnabled caches host name lookups in memory for all connections the tests make
+	DNSCacheEnabled bool
+
+	// DNSCacheTTL is how long a cached lookup is reused
+	DNSCacheTTL time.Duration
+
+	// FailFast cancels the remaining tests after the first failure
+	FailFast bool
+
+	// CertWarnDays warns about server certificates expiring within this many days
+	CertWarnDays int
+
+	// SourceIP binds outgoing connections to this local address; it takes precedence over LocalInterface
+	SourceIP string
+
+	// KeepaliveIdle is how long the TCP keepalive test leaves its connection idle
+	KeepaliveIdle time.Duration
+
+	// PingSourceAddr is the local IP address ping packets are sent from (empty = OS default)
+	PingSourceAddr string
+
+	// HTTPBearerToken is sent as a bearer token by the HTTP authentication check when no user name is given
+	HTTPBearerToken string
+
+	// IPv6Checker is the IPv6-only service the VPN check asks for the external IPv6 address
+	IPv6Checker string
+
+	// ResultsRotateSize rotates the results file before it grows beyond this many bytes (0 = disabled)
+	ResultsRotateSize int64
+
+	// ResultsRotateCount is the number of rotated results files kept
+	ResultsRotateCount int
+
+	// ProfilePath is where --profile writes its profile (empty = cpu.pprof or mem.pprof)
+	ProfilePath string
+
+	// RecordLatencySamples keeps the individual samples of the latency variance check in its result
+	RecordLatencySamples bool
+
+	// SpeedTestWarmup is the initial part of a speed test download left out of the measured speed
+	SpeedTestWarmup time.Duration
+
+	// DetectHTTP2Push repeats HTTP/2 tests over a connection that allows server push and counts the pushes
+	DetectHTTP2Push bool
+
+	// RPKIValidatorURL is the base URL of the RPKI validator's validity API
+	RPKIValidatorURL string
+
+	// OutputFilter selects the tests saved to the results file: "all", "failed-only" or "slow"
+	OutputFilter string
+
+	// SlowThreshold is the latency above which the "slow" output filter keeps a test
+	SlowThreshold time.Duration
+
+	// AlertEmail receives an email when tests fail (comma separated; empty = no alerts)
+	AlertEmail string
+
+	// AlertSMTPHost and AlertSMTPPort are the mail server used to send alerts
+	AlertSMTPHost string
+	AlertSMTPPort int
+
+	// AlertSMTPUser and AlertSMTPPassword authenticate to the mail server (empty = no authentication)
+	AlertSMTPUser     string
+	AlertSMTPPassword string
+
+	// AlertPacketLossThreshold is the ping packet loss percentage above which an alert is sent
+	AlertPacketLossThreshold float64
+
+	// HTTPVersion forces the HTTP version of HTTP tests: "1.1", "2" or "3" (empty = negotiate)
+	HTTPVersion string
+
+	// MaxResultFileSize is the largest results file that is written, in bytes (0 = unlimited)
+	MaxResultFileSize int64
+
+	// TrimOnOversize drops the oldest HTTP and speed tests until the results fit MaxResultFileSize
+	// instead of refusing to save them
+	TrimOnOversize bool
+
+	// HTTPSRedirectFollowLimit is the most redirects CheckHTTPSRedirect follows
+	HTTPSRedirectFollowLimit int
+
+	// TorExitListPort and TorExitListDestIP are the destination CheckTorExitNode asks the
+	// Tor exit list about: an IP is reported as an exit when it allows exiting to that address and port
+	TorExitListPort   int
+	TorExitListDestIP string
+
+	// ReportTitle and ReportDescription label saved reports
+	ReportTitle       string
+	ReportDescription string
+
+	// DNSTimeout bounds DNS lookups, separately from HTTPTimeout
+	DNSTimeout time.Duration
+
+	// ComputeBodyHash stores the SHA-256 of every HTTP test response body
+	ComputeBodyHash bool
+
+	// S3Bucket and S3Key upload saved results to S3 (empty key = results file name)
+	S3Bucket string
+	S3Key    string
+
+	// GCSBucket and GCSObject upload saved results to Google Cloud Storage (empty object = results file name)
+	GCSBucket string
+	GCSObject string
+
+	// HTTPFollowRedirects times every redirect hop of HTTP tests. The chain is capped by MaxRedirects,
+	// or by DefaultFollowRedirectLimit when that is 0, and must finish within HTTPTimeout.
+	HTTPFollowRedirects bool
+
+	// SOCKS5Proxy is the "host:port" of a SOCKS5 proxy TCP connections go through (empty = direct)
+	SOCKS5Proxy string
+
+	// HTTPMaxBodySize is how many response body bytes HTTP tests read (0 = unlimited)
+	HTTPMaxBodySize int64
+
+	// GrafanaURL receives an annotation for every failed test (empty = no annotations).
+	// GrafanaAPIKey authenticates the requests and GrafanaDashboardUID scopes them to a dashboard.
+	GrafanaURL          string
+	GrafanaAPIKey       string
+	GrafanaDashboardUID string
+
+	// EnableTrace records the full httptrace timing breakdown of every HTTP test
+	EnableTrace bool
+
+	// MeasureCompression makes HTTP tests ask for gzip or deflate explicitly and decode the body
+	// themselves, so the compressed size of responses the transport would decode is recorded too
+	MeasureCompression bool
+}
+
+// SLATarget describes the service level a tested URL is expected to meet
+type SLATarget struct {
+	// UptimePct is the minimum percentage of successful runs (e.g., 99.9)
+	UptimePct float64
 }
 
 // Default configuration constants
@@ -16,6 +243,9 @@ const (
 	// DefaultHTTPTimeout is the default timeout for HTTP requests
 	DefaultHTTPTimeout = 5 * time.Second
 
+	// DefaultHTTPConnectTimeout is the default timeout for establishing a TCP connection
+	DefaultHTTPConnectTimeout = 3 * time.Second
+
 	// DefaultPingCount is the number of ping packets to send
 	DefaultPingCount = 5
 
@@ -25,9 +255,129 @@ const (
 	// DefaultSpeedTestTimeout is the timeout for speed test requests
 	DefaultSpeedTestTimeout = 10 * time.Second
 
+	// DefaultSpeedTestWarmup skips roughly the TCP slow start phase of a download
+	DefaultSpeedTestWarmup = 2 * time.Second
+
 	// DefaultResultsFilePath is the default path for storing test results
 	DefaultResultsFilePath = "data.json"
 
+	// DefaultRequestsPerSecond disables HTTP test rate limiting
+	DefaultRequestsPerSecond = 0
+
+	// DefaultDNSResolver uses the system resolver
+	DefaultDNSResolver = ""
+
+	// DefaultKeepaliveIdle is the default idle period of the TCP keepalive test
+	DefaultKeepaliveIdle = 60 * time.Second
+
+	// DefaultDNSTimeout is the default timeout for DNS lookups
+	DefaultDNSTimeout = 5 * time.Second
+
+	// DefaultDNSCacheTTL is the default lifetime of cached DNS lookups
+	DefaultDNSCacheTTL = 5 * time.Minute
+
+	// DefaultThrottleTestGap is the default pause between throttle test downloads
+	DefaultThrottleTestGap = 5 * time.Second
+
+	// DefaultThrottleThresholdPct is the default slowdown percentage that indicates throttling
+	DefaultThrottleThresholdPct = 30.0
+
+	// DefaultRegressionTolerance is the default percentage a metric may worsen in diff mode
+	DefaultRegressionTolerance = 10.0
+
+	// DefaultBaselineFilePath is the default baseline results file used in diff mode
+	DefaultBaselineFilePath = "baseline.json"
+
+	// DefaultTLSMinVersion rejects TLS 1.0 and 1.1
+	DefaultTLSMinVersion = tls.VersionTLS12
+
+	// DefaultTLSMaxVersion leaves the maximum TLS version to the library
+	DefaultTLSMaxVersion = 0
+
+	// DefaultWorkerCount is the default number of concurrent workers for scanning tests
+	DefaultWorkerCount = 10
+
+	// DefaultMaxRedirects disables redirect following in HTTP tests
+	DefaultMaxRedirects = 0
+
+	// DefaultFollowRedirectLimit caps redirect chains when HTTPFollowRedirects is set without MaxRedirects
+	DefaultFollowRedirectLimit = 10
+
+	// DefaultPIDFilePath is the default PID file written in daemon mode
+	DefaultPIDFilePath = "uit.pid"
+
+	// DefaultLogFilePath is the default log file used in daemon mode
+	DefaultLogFilePath = "uit.log"
+
+	// DefaultScanPorts are the ports checked by --scan when -ports is not given
+	DefaultScanPorts = "22,80,443,8080"
+
+	// DefaultAlertSMTPPort is the mail submission port
+	DefaultAlertSMTPPort = 587
+
+	// DefaultAlertPacketLossThreshold alerts on more than 5% packet loss
+	DefaultAlertPacketLossThreshold = 5.0
+
+	// DefaultOutputFilter saves every test
+	DefaultOutputFilter = "all"
+
+	// DefaultSlowThreshold is the latency above which a test counts as slow
+	DefaultSlowThreshold = time.Second
+
+	// DefaultRPKIValidatorURL is the public RIPE NCC RPKI validator
+	DefaultRPKIValidatorURL = "https://rpki-validator.ripe.net/api/v1/validity"
+
+	// DefaultBGPAPIEndpoint is the public RIPE Stat looking glass API
+	DefaultBGPAPIEndpoint = "https://stat.ripe.net/data/looking-glass/data.json"
+
+	// DefaultCertWarnDays is the default certificate expiry warning window
+	DefaultCertWarnDays = 30
+
+	// DefaultRepeatCount runs every test once
+	DefaultRepeatCount = 1
+
+	// DefaultGeolocationAPI is the ipinfo.io JSON API
+	DefaultGeolocationAPI = "https://ipinfo.io/{ip}/json"
+
+	// DefaultResultsRotateSize rotates the results file at 10 MB
+	DefaultResultsRotateSize = 10 << 20
+
+	// DefaultResultsRotateCount keeps data.json.1 through data.json.5
+	DefaultResultsRotateCount = 5
+
+	// DefaultHTTPSRedirectFollowLimit follows up to 3 redirects looking for HTTPS
+	DefaultHTTPSRedirectFollowLimit = 3
+
+	// DefaultReportTitle is the default title of saved reports
+	DefaultReportTitle = "Ultimate Internet Test Report"
+
+	// DefaultTorExitListPort checks exits that allow plain HTTP
+	DefaultTorExitListPort = 80
+
+	// DefaultTorExitListDestIP is a public address most exit policies allow
+	DefaultTorExitListDestIP = "1.1.1.1"
+
+	// DefaultMaxResultFileSize refuses to write results files larger than 100 MB
+	DefaultMaxResultFileSize = 100 << 20
+
+	// DefaultHTTPMaxBodySize stops HTTP tests reading a response body after 1 MB
+	DefaultHTTPMaxBodySize = 1 << 20
+
+	// DefaultIPv6Checker returns the caller's address in plain text and is reachable only over IPv6
+	DefaultIPv6Checker = "https://api6.ipify.org"
+
+	// UserAgentProduct is the product token of the default User-Agent
+	UserAgentProduct = "ultimate-internet-test/1.0"
+
+	// DefaultSLAUptimePct is the default uptime target for SLA reports
+	DefaultSLAUptimePct = 99.9
+
+	// DefaultSLAReportPath is the default path for SLA compliance reports
+	DefaultSLAReportPath = "sla_report.json"
+
+	// DefaultResultsFormat is the default format results are saved in
+	DefaultResultsFormat = "json"
+
 	// BytesToBits conversion factor (for Mbps calculation)
 	BytesToBits = 8
 
@@ -38,6 +388,11 @@ const (
 	FilePermissions = 0644
 )
 
+// DefaultUserAgent returns the default User-Agent, e.g. "ultimate-internet-test/1.0 Go/go1.19"
+func DefaultUserAgent() string {
+	return UserAgentProduct + " Go/" + runtime.Version()
+}
+
 // New creates a new Config with default values
 func New() *Config {
 	return &Config{
@@ -46,5 +401,47 @@ func New() *Config {
 		PingTimeout:      DefaultPingTimeout,
 		SpeedTestTimeout: DefaultSpeedTestTimeout,
 		ResultsFilePath:  DefaultResultsFilePath,
+		ResultsFormat:    DefaultResultsFormat,
+
+		RequestsPerSecond:        DefaultRequestsPerSecond,
+		CaptureResponseHeaders:   false,
+		DNSResolver:              DefaultDNSResolver,
+		ThrottleTestGap:          DefaultThrottleTestGap,
+		ThrottleThresholdPct:     DefaultThrottleThresholdPct,
+		RegressionTolerance:      DefaultRegressionTolerance,
+		TLSMinVersion:            DefaultTLSMinVersion,
+		TLSMaxVersion:            DefaultTLSMaxVersion,
+		VPNCheckEnabled:          true,
+		SpeedCheckEnabled:        true,
+		PingCheckEnabled:         true,
+		WorkerCount:              DefaultWorkerCount,
+		MaxRedirects:             DefaultMaxRedirects,
+		PIDFilePath:              DefaultPIDFilePath,
+		LogFilePath:              DefaultLogFilePath,
+		HTTPConnectTimeout:       DefaultHTTPConnectTimeout,
+		BGPAPIEndpoint:           DefaultBGPAPIEndpoint,
+		UserAgent:                DefaultUserAgent(),
+		GeolocationAPI:           DefaultGeolocationAPI,
+		RepeatCount:              DefaultRepeatCount,
+		DNSCacheTTL:              DefaultDNSCacheTTL,
+		DNSTimeout:               DefaultDNSTimeout,
+		CertWarnDays:             DefaultCertWarnDays,
+		KeepaliveIdle:            DefaultKeepaliveIdle,
+		IPv6Checker:              DefaultIPv6Checker,
+		ResultsRotateSize:        DefaultResultsRotateSize,
+		ResultsRotateCount:       DefaultResultsRotateCount,
+		MaxResultFileSize:        DefaultMaxResultFileSize,
+		HTTPMaxBodySize:          DefaultHTTPMaxBodySize,
+		HTTPSRedirectFollowLimit: DefaultHTTPSRedirectFollowLimit,
+		TorExitListPort:          DefaultTorExitListPort,
+		TorExitListDestIP:        DefaultTorExitListDestIP,
+		ReportTitle:              DefaultReportTitle,
+		SpeedTestWarmup:          DefaultSpeedTestWarmup,
+		RPKIValidatorURL:         DefaultRPKIValidatorURL,
+		OutputFilter:             DefaultOutputFilter,
+		SlowThreshold:            DefaultSlowThreshold,
+
+		AlertSMTPPort:            DefaultAlertSMTPPort,
+		AlertPacketLossThreshold: DefaultAlertPacketLossThreshold,
 	}
 }
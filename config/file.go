@@ -0,0 +1,239 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// EnvResultsFormat overrides the results format from the config file
+const EnvResultsFormat = "UIT_RESULTS_FORMAT"
+
+// fileConfig is the JSON representation of a config file.
+// Pointer fields distinguish values left out of the file from zero values.
+type fileConfig struct {
+	HTTPTimeout            *duration `json:"http_timeout"`
+	HTTPConnectTimeout     *duration `json:"http_connect_timeout"`
+	PingCount              *int      `json:"ping_count"`
+	PingTimeout            *duration `json:"ping_timeout"`
+	SpeedTestTimeout       *duration `json:"speed_test_timeout"`
+	ResultsFilePath        *string   `json:"results_file_path"`
+	ResultsFormat          *string   `json:"results_format"`
+	RequestsPerSecond      *float64  `json:"requests_per_second"`
+	CaptureResponseHeaders *bool     `json:"capture_response_headers"`
+	DNSResolver            *string   `json:"dns_resolver"`
+	LocalInterface         *string   `json:"local_interface"`
+	MaxRedirects           *int      `json:"max_redirects"`
+	WorkerCount            *int      `json:"worker_count"`
+	VPNCheckEnabled        *bool     `json:"vpn_check_enabled"`
+	SpeedCheckEnabled      *bool     `json:"speed_check_enabled"`
+	PingCheckEnabled       *bool     `json:"ping_check_enabled"`
+	DNSTestEnabled         *bool     `json:"dns_test_enabled"`
+	BGPAPIEndpoint         *string   `json:"bgp_api_endpoint"`
+	WatchInterval          *duration `json:"watch_interval"`
+	WatchJitter            *duration `json:"watch_jitter"`
+	Debug                  *bool     `json:"debug"`
+	UserAgent              *string   `json:"user_agent"`
+	EnableGeolocation      *bool     `json:"enable_geolocation"`
+	GeolocationAPI         *string   `json:"geolocation_api"`
+	RepeatCount            *int      `json:"repeat_count"`
+	RepeatDelay            *duration `json:"repeat_delay"`
+	TestCaching            *bool     `json:"test_caching"`
+	DNSCacheEnabled        *bool     `json:"dns_cache_enabled"`
+	DNSCacheTTL            *duration `json:"dns_cache_ttl"`
+	FailFast               *bool     `json:"fail_fast"`
+	CertWarnDays           *int      `json:"cert_warn_days"`
+	SourceIP               *string   `json:"source_ip"`
+	KeepaliveIdle          *duration `json:"keepalive_idle"`
+	PingSourceAddr         *string   `json:"ping_source_addr"`
+	HTTPBearerToken        *string   `json:"http_bearer_token"`
+	IPv6Checker            *string   `json:"ipv6_checker"`
+	ResultsRotateSize      *int64    `json:"results_rotate_size"`
+	ResultsRotateCount     *int      `json:"results_rotate_count"`
+	RecordLatencySamples   *bool     `json:"record_latency_samples"`
+	SpeedTestWarmup        *duration `json:"speed_test_warmup"`
+	DetectHTTP2Push        *bool     `json:"detect_http2_push"`
+	RPKIValidatorURL       *string   `json:"rpki_validator_url"`
+	OutputFilter           *string   `json:"output_filter"`
+	SlowThreshold          *duration `json:"slow_threshold"`
+
+	AlertEmail               *string  `json:"alert_email"`
+	AlertSMTPHost            *string  `json:"alert_smtp_host"`
+	AlertSMTPPort            *int     `json:"alert_smtp_port"`
+	AlertSMTPUser            *string  `json:"alert_smtp_user"`
+	AlertSMTPPassword        *string  `json:"alert_smtp_password"`
+	AlertPacketLossThreshold *float64 `json:"alert_packet_loss_threshold"`
+
+	HTTPVersion       *string `json:"http_version"`
+	MaxResultFileSize *int64  `json:"max_result_file_size"`
+	TrimOnOversize    *bool   `json:"trim_on_oversize"`
+
+	HTTPSRedirectFollowLimit *int    `json:"https_redirect_follow_limit"`
+	TorExitListPort          *int    `json:"tor_exit_list_port"`
+	TorExitListDestIP        *string `json:"tor_exit_list_dest_ip"`
+
+	ReportTitle       *string `json:"report_title"`
+	ReportDescription *string `json:"report_description"`
+
+	DNSTimeout      *duration `json:"dns_timeout"`
+	ComputeBodyHash *bool     `json:"compute_body_hash"`
+
+	S3Bucket  *string `json:"s3_bucket"`
+	S3Key     *string `json:"s3_key"`
+	GCSBucket *string `json:"gcs_bucket"`
+	GCSObject *string `json:"gcs_object"`
+
+	HTTPFollowRedirects *bool   `json:"http_follow_redirects"`
+	SOCKS5Proxy         *string `json:"socks5_proxy"`
+	HTTPMaxBodySize     *int64  `json:"http_max_body_size"`
+
+	GrafanaURL          *string `json:"grafana_url"`
+	GrafanaAPIKey       *string `json:"grafana_api_key"`
+	GrafanaDashboardUID *string `json:"grafana_dashboard_uid"`
+
+	EnableTrace        *bool `json:"enable_trace"`
+	MeasureCompression *bool `json:"measure_compression"`
+}
+
+// duration unmarshals from a Go duration string such as "5s" or "1m30s"
+type duration time.Duration
+
+// UnmarshalJSON implements json.Unmarshaler
+func (d *duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"5s\": %w", err)
+	}
+
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+
+	*d = duration(parsed)
+	return nil
+}
+
+// LoadFromFile creates a Config from the defaults overridden by the values in a JSON config file.
+// Values of the wrong type and values failing Validate are all reported together as *FieldError
+// values joined with errors.Join. The Config is still returned in that case, with the invalid
+// typed values left at their defaults; it is nil only when the file cannot be read or parsed.
+func LoadFromFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	var fc fileConfig
+	errs := decodeFields(raw, &fc)
+
+	cfg := New()
+	fc.apply(cfg)
+
+	errs = append(errs, cfg.fieldErrors()...)
+	return cfg, errors.Join(errs...)
+}
+
+// apply copies every value present in the file onto cfg
+func (fc *fileConfig) apply(cfg *Config) {
+	setDuration(&cfg.HTTPTimeout, fc.HTTPTimeout)
+	setDuration(&cfg.HTTPConnectTimeout, fc.HTTPConnectTimeout)
+	setDuration(&cfg.PingTimeout, fc.PingTimeout)
+	setDuration(&cfg.SpeedTestTimeout, fc.SpeedTestTimeout)
+	setDuration(&cfg.SpeedTestWarmup, fc.SpeedTestWarmup)
+	setDuration(&cfg.WatchInterval, fc.WatchInterval)
+	setDuration(&cfg.WatchJitter, fc.WatchJitter)
+	setDuration(&cfg.RepeatDelay, fc.RepeatDelay)
+	setDuration(&cfg.DNSCacheTTL, fc.DNSCacheTTL)
+	setDuration(&cfg.KeepaliveIdle, fc.KeepaliveIdle)
+	set(&cfg.PingCount, fc.PingCount)
+	set(&cfg.ResultsFilePath, fc.ResultsFilePath)
+	set(&cfg.ResultsFormat, fc.ResultsFormat)
+	set(&cfg.RequestsPerSecond, fc.RequestsPerSecond)
+	set(&cfg.CaptureResponseHeaders, fc.CaptureResponseHeaders)
+	set(&cfg.DNSResolver, fc.DNSResolver)
+	set(&cfg.LocalInterface, fc.LocalInterface)
+	set(&cfg.MaxRedirects, fc.MaxRedirects)
+	set(&cfg.WorkerCount, fc.WorkerCount)
+	set(&cfg.VPNCheckEnabled, fc.VPNCheckEnabled)
+	set(&cfg.SpeedCheckEnabled, fc.SpeedCheckEnabled)
+	set(&cfg.PingCheckEnabled, fc.PingCheckEnabled)
+	set(&cfg.DNSTestEnabled, fc.DNSTestEnabled)
+	set(&cfg.BGPAPIEndpoint, fc.BGPAPIEndpoint)
+	set(&cfg.RPKIValidatorURL, fc.RPKIValidatorURL)
+	set(&cfg.OutputFilter, fc.OutputFilter)
+	setDuration(&cfg.SlowThreshold, fc.SlowThreshold)
+	set(&cfg.AlertEmail, fc.AlertEmail)
+	set(&cfg.AlertSMTPHost, fc.AlertSMTPHost)
+	set(&cfg.AlertSMTPPort, fc.AlertSMTPPort)
+	set(&cfg.AlertSMTPUser, fc.AlertSMTPUser)
+	set(&cfg.AlertSMTPPassword, fc.AlertSMTPPassword)
+	set(&cfg.AlertPacketLossThreshold, fc.AlertPacketLossThreshold)
+	set(&cfg.Debug, fc.Debug)
+	set(&cfg.UserAgent, fc.UserAgent)
+	set(&cfg.EnableGeolocation, fc.EnableGeolocation)
+	set(&cfg.GeolocationAPI, fc.GeolocationAPI)
+	set(&cfg.RepeatCount, fc.RepeatCount)
+	set(&cfg.TestCaching, fc.TestCaching)
+	set(&cfg.DNSCacheEnabled, fc.DNSCacheEnabled)
+	set(&cfg.FailFast, fc.FailFast)
+	set(&cfg.CertWarnDays, fc.CertWarnDays)
+	set(&cfg.SourceIP, fc.SourceIP)
+	set(&cfg.PingSourceAddr, fc.PingSourceAddr)
+	set(&cfg.HTTPBearerToken, fc.HTTPBearerToken)
+	set(&cfg.IPv6Checker, fc.IPv6Checker)
+	set(&cfg.ResultsRotateSize, fc.ResultsRotateSize)
+	set(&cfg.ResultsRotateCount, fc.ResultsRotateCount)
+	set(&cfg.RecordLatencySamples, fc.RecordLatencySamples)
+	set(&cfg.DetectHTTP2Push, fc.DetectHTTP2Push)
+	set(&cfg.HTTPVersion, fc.HTTPVersion)
+	set(&cfg.MaxResultFileSize, fc.MaxResultFileSize)
+	set(&cfg.TrimOnOversize, fc.TrimOnOversize)
+	set(&cfg.HTTPSRedirectFollowLimit, fc.HTTPSRedirectFollowLimit)
+	set(&cfg.TorExitListPort, fc.TorExitListPort)
+	set(&cfg.TorExitListDestIP, fc.TorExitListDestIP)
+	set(&cfg.ReportTitle, fc.ReportTitle)
+	set(&cfg.ReportDescription, fc.ReportDescription)
+	setDuration(&cfg.DNSTimeout, fc.DNSTimeout)
+	set(&cfg.ComputeBodyHash, fc.ComputeBodyHash)
+	set(&cfg.S3Bucket, fc.S3Bucket)
+	set(&cfg.S3Key, fc.S3Key)
+	set(&cfg.GCSBucket, fc.GCSBucket)
+	set(&cfg.GCSObject, fc.GCSObject)
+	set(&cfg.HTTPFollowRedirects, fc.HTTPFollowRedirects)
+	set(&cfg.SOCKS5Proxy, fc.SOCKS5Proxy)
+	set(&cfg.HTTPMaxBodySize, fc.HTTPMaxBodySize)
+	set(&cfg.GrafanaURL, fc.GrafanaURL)
+	set(&cfg.GrafanaAPIKey, fc.GrafanaAPIKey)
+	set(&cfg.GrafanaDashboardUID, fc.GrafanaDashboardUID)
+	set(&cfg.EnableTrace, fc.EnableTrace)
+	set(&cfg.MeasureCompression, fc.MeasureCompression)
+}
+
+// ApplyEnv overrides config values with those set in the environment
+func (c *Config) ApplyEnv() {
+	if format := os.Getenv(EnvResultsFormat); format != "" {
+		c.ResultsFormat = format
+	}
+}
+
+// set assigns *src to *dst when src is present
+func set[T any](dst *T, src *T) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+// setDuration assigns a parsed duration to dst when src is present
+func setDuration(dst *time.Duration, src *duration) {
+	if src != nil {
+		*dst = time.Duration(*src)
+	}
+}
@@ -0,0 +1,156 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// FieldError describes a config value outside its valid range or of the wrong type.
+// Field is the name of the value in the config file.
+type FieldError struct {
+	Field    string
+	Value    string
+	Expected string
+}
+
+// Error implements error
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: got %s, want %s", e.Field, e.Value, e.Expected)
+}
+
+// Validate checks the config values against their valid ranges and returns every
+// violation as a *FieldError, joined with errors.Join. It returns nil for a valid config.
+func (c *Config) Validate() error {
+	return errors.Join(c.fieldErrors()...)
+}
+
+// fieldErrors returns a *FieldError for every config value outside its valid range
+func (c *Config) fieldErrors() []error {
+	var errs []error
+
+	positive := func(field string, d time.Duration) {
+		if d <= 0 {
+			errs = append(errs, &FieldError{Field: field, Value: d.String(), Expected: "a duration greater than 0"})
+		}
+	}
+	nonNegative := func(field string, d time.Duration) {
+		if d < 0 {
+			errs = append(errs, &FieldError{Field: field, Value: d.String(), Expected: "a duration of 0 or more"})
+		}
+	}
+	atLeast := func(field string, n int64, min int64) {
+		if n < min {
+			errs = append(errs, &FieldError{Field: field, Value: fmt.Sprint(n), Expected: fmt.Sprintf("an integer of %d or more", min)})
+		}
+	}
+	port := func(field string, n int) {
+		if n < 1 || n > 65535 {
+			errs = append(errs, &FieldError{Field: field, Value: fmt.Sprint(n), Expected: "a port between 1 and 65535"})
+		}
+	}
+	percent := func(field string, f float64) {
+		if f < 0 || f > 100 {
+			errs = append(errs, &FieldError{Field: field, Value: fmt.Sprint(f), Expected: "a percentage between 0 and 100"})
+		}
+	}
+
+	positive("http_timeout", c.HTTPTimeout)
+	positive("http_connect_timeout", c.HTTPConnectTimeout)
+	positive("ping_timeout", c.PingTimeout)
+	positive("speed_test_timeout", c.SpeedTestTimeout)
+	positive("dns_timeout", c.DNSTimeout)
+	positive("dns_cache_ttl", c.DNSCacheTTL)
+	nonNegative("watch_interval", c.WatchInterval)
+	nonNegative("watch_jitter", c.WatchJitter)
+	nonNegative("repeat_delay", c.RepeatDelay)
+	nonNegative("keepalive_idle", c.KeepaliveIdle)
+	nonNegative("speed_test_warmup", c.SpeedTestWarmup)
+	nonNegative("slow_threshold", c.SlowThreshold)
+
+	atLeast("ping_count", int64(c.PingCount), 1)
+	atLeast("worker_count", int64(c.WorkerCount), 1)
+	atLeast("repeat_count", int64(c.RepeatCount), 1)
+	atLeast("max_redirects", int64(c.MaxRedirects), 0)
+	atLeast("cert_warn_days", int64(c.CertWarnDays), 0)
+	atLeast("results_rotate_size", c.ResultsRotateSize, 0)
+	atLeast("results_rotate_count", int64(c.ResultsRotateCount), 0)
+	atLeast("max_result_file_size", c.MaxResultFileSize, 0)
+	atLeast("https_redirect_follow_limit", int64(c.HTTPSRedirectFollowLimit), 0)
+	atLeast("http_max_body_size", c.HTTPMaxBodySize, 0)
+
+	port("alert_smtp_port", c.AlertSMTPPort)
+	port("tor_exit_list_port", c.TorExitListPort)
+	percent("alert_packet_loss_threshold", c.AlertPacketLossThreshold)
+
+	if c.RequestsPerSecond < 0 {
+		errs = append(errs, &FieldError{Field: "requests_per_second", Value: fmt.Sprint(c.RequestsPerSecond), Expected: "a rate of 0 or more"})
+	}
+	if c.TLSMaxVersion != 0 && c.TLSMinVersion > c.TLSMaxVersion {
+		errs = append(errs, &FieldError{Field: "tls_min_version", Value: fmt.Sprintf("%#x", c.TLSMinVersion), Expected: "a version no higher than the maximum TLS version"})
+	}
+	if _, _, err := net.SplitHostPort(c.SOCKS5Proxy); c.SOCKS5Proxy != "" && err != nil {
+		errs = append(errs, &FieldError{Field: "socks5_proxy", Value: c.SOCKS5Proxy, Expected: "a host:port address"})
+	}
+
+	return errs
+}
+
+// decodeFields decodes the config file fields in raw into fc one by one, so a value of the wrong
+// type is reported as a *FieldError without hiding problems with the other fields
+func decodeFields(raw map[string]json.RawMessage, fc *fileConfig) []error {
+	var errs []error
+
+	// Keys match case-insensitively like they do with json.Unmarshal into a struct
+	fields := make(map[string]json.RawMessage, len(raw))
+	for key, value := range raw {
+		fields[strings.ToLower(key)] = value
+	}
+
+	v := reflect.ValueOf(fc).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+
+		value, ok := fields[name]
+		if !ok {
+			continue
+		}
+		// Decode into a fresh value so a failed decode leaves the field unset
+		decoded := reflect.New(field.Type)
+		if err := json.Unmarshal(value, decoded.Interface()); err != nil {
+			errs = append(errs, &FieldError{Field: name, Value: string(value), Expected: expectedType(field.Type)})
+			continue
+		}
+		v.Field(i).Set(decoded.Elem())
+	}
+
+	return errs
+}
+
+// expectedType describes the JSON value a fileConfig field of type t accepts
+func expectedType(t reflect.Type) string {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(duration(0)) {
+		return `a duration string like "5s"`
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Int, reflect.Int64:
+		return "an integer"
+	case reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	}
+	return t.String()
+}
@@ -0,0 +1,14 @@
+//go:build !unix
+
+package main
+
+import (
+	"errors"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+)
+
+// daemonize is not supported outside unix systems; use a service manager instead
+func daemonize(cfg *config.Config) error {
+	return errors.New("daemon mode is only supported on unix systems")
+}
@@ -0,0 +1,57 @@
+//go:build unix
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+)
+
+// daemonize re-executes the current binary detached from the terminal in a new session,
+// with stdout and stderr redirected to cfg.LogFilePath, and writes the child PID to cfg.PIDFilePath.
+// The caller should exit once daemonize returns successfully.
+func daemonize(cfg *config.Config) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("cannot locate executable: %w", err)
+	}
+
+	logFile, err := os.OpenFile(cfg.LogFilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, config.FilePermissions)
+	if err != nil {
+		return fmt.Errorf("cannot open log file: %w", err)
+	}
+	defer logFile.Close()
+
+	devNull, err := os.Open(os.DevNull)
+	if err != nil {
+		return fmt.Errorf("cannot open %s: %w", os.DevNull, err)
+	}
+	defer devNull.Close()
+
+	process, err := os.StartProcess(executable, os.Args, &os.ProcAttr{
+		Env:   append(os.Environ(), daemonEnv+"=1"),
+		Files: []*os.File{devNull, logFile, logFile},
+		Sys:   &syscall.SysProcAttr{Setsid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("cannot start daemon: %w", err)
+	}
+
+	// Give the child a moment and confirm it is still alive
+	time.Sleep(daemonStartupGrace)
+	if err := process.Signal(syscall.Signal(0)); err != nil {
+		return fmt.Errorf("daemon exited during startup, see %s: %w", cfg.LogFilePath, err)
+	}
+
+	if err := os.WriteFile(cfg.PIDFilePath, []byte(strconv.Itoa(process.Pid)+"\n"), config.FilePermissions); err != nil {
+		return fmt.Errorf("cannot write PID file: %w", err)
+	}
+
+	fmt.Printf("Started daemon with PID %d (log: %s, pid file: %s)\n", process.Pid, cfg.LogFilePath, cfg.PIDFilePath)
+	return process.Release()
+}
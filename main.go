@@ -1,76 +1,770 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
+	"net"
+	neturl "net/url"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/ehsanghaffar/ultimate-internet-test/config"
 	"github.com/ehsanghaffar/ultimate-internet-test/modules"
 	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+	"go.opentelemetry.io/otel"
+)
+
+const (
+	// daemonEnv marks a process started by --daemon so it does not fork again
+	daemonEnv = "UIT_DAEMONIZED"
+
+	// daemonStartupGrace is how long the parent waits before confirming the daemon started
+	daemonStartupGrace = 200 * time.Millisecond
+)
+
+// stringList is a repeatable string flag
+type stringList []string
+
+// String implements flag.Value
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+// Set implements flag.Value by appending each occurrence
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+var (
+	// certPins holds the SPKI pins provided via --pin
+	certPins stringList
+
+	// SLA report flags
+	slaReport     bool
+	slaReportPath string
+	slaTargetPct  float64
+
+	// showVersion prints build information and exits
+	showVersion bool
+
+	// printSchema prints the JSON Schema of the results and exits
+	printSchema bool
+
+	// Diff mode flags
+	diffMode     bool
+	baselinePath string
+
+	// Port scan flags
+	scanHost  string
+	scanPorts string
+
+	// runDaemon detaches the process and runs it in the background
+	runDaemon bool
+
+	// Flags disabling individual test types
+	noVPNCheck   bool
+	noSpeedCheck bool
+	noPingCheck  bool
+
+	// configPath is the JSON config file given via --config
+	configPath string
+
+	// debugEnabled is set from cfg.Debug and gates debugf
+	debugEnabled bool
+
+	// HTTP authentication check flags; the token overrides cfg.HTTPBearerToken
+	authCredentials string
+	bearerToken     string
+
+	// profileKind is the profile ("cpu" or "mem") given via --profile
+	profileKind string
+
+	// Scheduling flags delaying the tests until a time of day
+	scheduleAt string
+	scheduleTZ string
+
+	// mergePattern is the glob of results files combined by --merge-results
+	mergePattern string
+
+	// validateConfig checks the configuration and exits
+	validateConfig bool
+
+	// compareProtocols runs speed tests of the URL arguments over every HTTP version
+	compareProtocols bool
 )
 
 func main() {
+	// Initialize configuration with defaults, overridden by the config file and the environment.
+	// The config file is located before flag parsing so the remaining flags can override it.
+	// Invalid values only abort the run once flags are parsed, so --validate-config can list them.
+	cfg := config.New()
+	var configErr error
+	if path := configPathFromArgs(os.Args[1:]); path != "" {
+		loaded, err := config.LoadFromFile(path)
+		if loaded == nil {
+			log.Fatalf("Error loading config: %v\n", err)
+		}
+		cfg, configErr = loaded, err
+	}
+	cfg.ApplyEnv()
+
+	flag.StringVar(&configPath, "config", "", "JSON config file; flags override its values")
+	flag.StringVar(&cfg.ResultsFormat, "format", cfg.ResultsFormat, "results format: json, csv, html or influx")
+
+	flag.Float64Var(&cfg.RequestsPerSecond, "rate-limit", cfg.RequestsPerSecond, "maximum HTTP test requests per second (0 = unlimited)")
+	flag.BoolVar(&cfg.CaptureResponseHeaders, "capture-headers", cfg.CaptureResponseHeaders, "store response headers in HTTP test results")
+	flag.Var(&certPins, "pin", "accepted certificate pin as SHA256:<base64> (repeatable)")
+	flag.BoolVar(&slaReport, "sla-report", false, "generate an SLA compliance report from the results history and exit")
+	flag.StringVar(&slaReportPath, "sla-report-path", config.DefaultSLAReportPath, "path of the SLA report (.json or .csv)")
+	flag.Float64Var(&slaTargetPct, "sla-target", config.DefaultSLAUptimePct, "uptime percentage required for SLA compliance")
+	flag.StringVar(&cfg.LocalInterface, "interface", cfg.LocalInterface, "bind outgoing connections to this network interface")
+	flag.BoolVar(&diffMode, "diff-mode", false, "compare results against a baseline and exit non-zero on regressions")
+	flag.StringVar(&baselinePath, "baseline", config.DefaultBaselineFilePath, "baseline results file used in diff mode")
+	flag.Float64Var(&cfg.RegressionTolerance, "regression-tolerance", cfg.RegressionTolerance, "percentage a metric may worsen before it counts as a regression")
+	flag.Func("tls-min-version", "minimum TLS version for HTTP tests (1.0, 1.1, 1.2, 1.3)", func(v string) (err error) {
+		cfg.TLSMinVersion, err = utils.ParseTLSVersion(v)
+		return err
+	})
+	flag.Func("tls-max-version", "maximum TLS version for HTTP tests (1.0, 1.1, 1.2, 1.3)", func(v string) (err error) {
+		cfg.TLSMaxVersion, err = utils.ParseTLSVersion(v)
+		return err
+	})
+	flag.IntVar(&cfg.MaxRedirects, "max-redirects", cfg.MaxRedirects, "number of redirects HTTP tests follow (0 = none)")
+	flag.BoolVar(&cfg.DNSTestEnabled, "test-dns", cfg.DNSTestEnabled, "run DNS tests for the hosts of all HTTP test URLs")
+	flag.BoolVar(&noVPNCheck, "no-vpn-check", !cfg.VPNCheckEnabled, "skip the VPN/proxy detection test")
+	flag.BoolVar(&noSpeedCheck, "no-speed-check", !cfg.SpeedCheckEnabled, "skip the speed tests")
+	flag.BoolVar(&noPingCheck, "no-ping-check", !cfg.PingCheckEnabled, "skip the ping tests")
+	flag.StringVar(&scanHost, "scan", "", "scan TCP ports of this host and exit")
+	flag.StringVar(&scanPorts, "ports", config.DefaultScanPorts, "ports for --scan, e.g. 22,80,443,8000-8010")
+	flag.DurationVar(&cfg.HTTPConnectTimeout, "connect-timeout", cfg.HTTPConnectTimeout, "timeout for establishing a TCP connection")
+	flag.BoolVar(&runDaemon, "daemon", false, "run in the background and write a PID file")
+	flag.StringVar(&cfg.LogFilePath, "log-file", cfg.LogFilePath, "log file used by --daemon")
+	flag.StringVar(&cfg.PIDFilePath, "pid-file", cfg.PIDFilePath, "PID file written by --daemon")
+	flag.DurationVar(&cfg.WatchInterval, "watch", cfg.WatchInterval, "repeat the default tests at this interval, e.g. 5m (0 = run once)")
+	flag.DurationVar(&cfg.WatchJitter, "check-interval-jitter", cfg.WatchJitter, "add a random delay of up to this duration to every --watch interval")
+	flag.BoolVar(&cfg.Debug, "debug", cfg.Debug, "enable debug logging")
+	flag.StringVar(&cfg.UserAgent, "user-agent", cfg.UserAgent, "User-Agent header sent with HTTP requests")
+	flag.StringVar(&cfg.UserAgent, "A", cfg.UserAgent, "shorthand for --user-agent")
+	flag.BoolVar(&cfg.EnableGeolocation, "geolocation", cfg.EnableGeolocation, "look up the location of the external IP found by the VPN check")
+	flag.IntVar(&cfg.RepeatCount, "repeat", cfg.RepeatCount, "run each HTTP and speed test this many times and report min/max/avg")
+	flag.DurationVar(&cfg.RepeatDelay, "repeat-delay", cfg.RepeatDelay, "pause between repeats of the same test")
+	flag.BoolVar(&cfg.TestCaching, "test-caching", cfg.TestCaching, "revalidate HTTP test responses to detect caching (sends a second request)")
+	flag.BoolVar(&cfg.DNSCacheEnabled, "dns-cache", cfg.DNSCacheEnabled, "cache DNS lookups in memory across tests")
+	flag.DurationVar(&cfg.DNSCacheTTL, "dns-cache-ttl", cfg.DNSCacheTTL, "how long cached DNS lookups are reused")
+	flag.BoolVar(&cfg.FailFast, "fail-fast", cfg.FailFast, "stop all tests after the first failure and exit with code 1")
+	flag.IntVar(&cfg.CertWarnDays, "cert-warn-days", cfg.CertWarnDays, "warn when a certificate expires within this many days")
+	flag.Func("connect-via", "make connections from this network interface or source IP", func(v string) error {
+		if net.ParseIP(v) != nil {
+			cfg.SourceIP = v
+		} else {
+			cfg.LocalInterface = v
+		}
+		return nil
+	})
+	flag.StringVar(&cfg.PingSourceAddr, "ping-source", cfg.PingSourceAddr, "local IP address to send ping packets from")
+	flag.StringVar(&authCredentials, "auth", "", "check HTTP Basic authentication of the given URLs with user:pass")
+	flag.StringVar(&bearerToken, "bearer-token", "", "check bearer token authentication of the given URLs with this token")
+	flag.Int64Var(&cfg.ResultsRotateSize, "results-rotate-size", cfg.ResultsRotateSize, "rotate the results file before it exceeds this many bytes (0 disables)")
+	flag.IntVar(&cfg.ResultsRotateCount, "results-rotate-count", cfg.ResultsRotateCount, "number of rotated results files to keep")
+	flag.Int64Var(&cfg.MaxResultFileSize, "max-results-size", cfg.MaxResultFileSize, "refuse to write a results file larger than this many bytes (0 = unlimited)")
+	flag.BoolVar(&cfg.TrimOnOversize, "trim-oversize", cfg.TrimOnOversize, "drop the oldest HTTP and speed tests instead of refusing oversized results")
+	flag.StringVar(&profileKind, "profile", "", "write a pprof profile of the run: cpu or mem")
+	flag.StringVar(&cfg.ProfilePath, "profile-path", cfg.ProfilePath, "profile output file (default cpu.pprof or mem.pprof)")
+	flag.DurationVar(&cfg.SpeedTestWarmup, "speed-warmup", cfg.SpeedTestWarmup, "initial part of each speed test download left out of the measured speed")
+	flag.BoolVar(&cfg.DetectHTTP2Push, "detect-push", cfg.DetectHTTP2Push, "count HTTP/2 server pushes with an extra request per HTTP/2 test")
+	flag.StringVar(&cfg.OutputFilter, "output-filter", cfg.OutputFilter, "tests to save to the results file: all, failed-only or slow")
+	flag.DurationVar(&cfg.SlowThreshold, "slow-threshold", cfg.SlowThreshold, "latency above which --output-filter slow keeps a test")
+	flag.StringVar(&cfg.AlertEmail, "alert-email", cfg.AlertEmail, "email these comma separated addresses when tests fail")
+	flag.StringVar(&cfg.AlertSMTPHost, "alert-smtp-host", cfg.AlertSMTPHost, "mail server used to send alert emails")
+	flag.IntVar(&cfg.AlertSMTPPort, "alert-smtp-port", cfg.AlertSMTPPort, "port of the alert mail server")
+	flag.StringVar(&cfg.AlertSMTPUser, "alert-smtp-user", cfg.AlertSMTPUser, "user name for the alert mail server")
+	flag.Float64Var(&cfg.AlertPacketLossThreshold, "alert-packet-loss", cfg.AlertPacketLossThreshold, "ping packet loss percentage above which an alert email is sent")
+	flag.StringVar(&cfg.HTTPVersion, "http-version", cfg.HTTPVersion, "force the HTTP version of HTTP tests: 1.1, 2 or 3 (default: negotiate)")
+	flag.StringVar(&scheduleAt, "schedule-at", "", "wait until this time of day (HH:MM, 24h) before running the tests")
+	flag.StringVar(&scheduleTZ, "schedule-tz", "UTC", "time zone of --schedule-at, e.g. Europe/Berlin or Local")
+	flag.StringVar(&cfg.ReportTitle, "report-title", cfg.ReportTitle, "title stored in saved reports")
+	flag.StringVar(&cfg.ReportDescription, "report-description", cfg.ReportDescription, "description stored in saved reports")
+	flag.DurationVar(&cfg.DNSTimeout, "dns-timeout", cfg.DNSTimeout, "timeout for DNS lookups")
+	flag.StringVar(&cfg.S3Bucket, "s3-bucket", cfg.S3Bucket, "also upload saved results to this S3 bucket (standard AWS SDK credentials)")
+	flag.StringVar(&cfg.S3Key, "s3-key", cfg.S3Key, "object key of uploaded S3 results (default: the results file name)")
+	flag.StringVar(&cfg.GCSBucket, "gcs-bucket", cfg.GCSBucket, "also upload saved results to this Google Cloud Storage bucket (application default credentials)")
+	flag.StringVar(&cfg.GCSObject, "gcs-object", cfg.GCSObject, "object name of uploaded GCS results (default: the results file name)")
+	flag.BoolVar(&cfg.HTTPFollowRedirects, "follow-redirects", cfg.HTTPFollowRedirects, "follow HTTP test redirects up to --max-redirects (default 10) and time each hop")
+	flag.StringVar(&cfg.SOCKS5Proxy, "socks5-proxy", cfg.SOCKS5Proxy, "route TCP connections of HTTP, speed and VPN tests through this SOCKS5 proxy (host:port)")
+	flag.Int64Var(&cfg.HTTPMaxBodySize, "http-max-body-size", cfg.HTTPMaxBodySize, "bytes of each HTTP test response body to read (0 = unlimited)")
+	flag.StringVar(&cfg.GrafanaURL, "grafana-url", cfg.GrafanaURL, "post an annotation for every failed test to this Grafana instance")
+	flag.StringVar(&cfg.GrafanaAPIKey, "grafana-api-key", cfg.GrafanaAPIKey, "API key or service account token for --grafana-url")
+	flag.StringVar(&cfg.GrafanaDashboardUID, "grafana-dashboard-uid", cfg.GrafanaDashboardUID, "attach Grafana annotations to this dashboard (default: organization wide)")
+	flag.BoolVar(&cfg.EnableTrace, "trace", cfg.EnableTrace, "store the DNS, connect, TLS, server and transfer timing breakdown of HTTP tests")
+	flag.StringVar(&mergePattern, "merge-results", "", "merge the results files matching this glob pattern into the results file and exit")
+	flag.BoolVar(&cfg.MeasureCompression, "measure-compression", cfg.MeasureCompression, "request gzip or deflate explicitly and record the compressed and decoded size of HTTP test responses")
+	flag.BoolVar(&cfg.ComputeBodyHash, "body-hash", cfg.ComputeBodyHash, "store the SHA-256 of HTTP test response bodies and warn in --watch mode when it changes")
+	flag.BoolVar(&compareProtocols, "compare-protocols", false, "run speed tests of the given URLs over HTTP/1.1, HTTP/2 and HTTP/3 and compare them")
+	flag.BoolVar(&validateConfig, "validate-config", false, "check the config file and flags, list every invalid value and exit")
+	flag.BoolVar(&printSchema, "print-schema", false, "print the JSON Schema of the results file and exit")
+	flag.BoolVar(&showVersion, "version", false, "print version information and exit")
 	flag.Parse()
 
+	if validateConfig {
+		runValidateConfig(cfg, configErr)
+		return
+	}
+	if configErr != nil {
+		log.Fatalf("Invalid config file:\n%v\n", configErr)
+	}
+
+	cfg.VPNCheckEnabled = !noVPNCheck
+	cfg.SpeedCheckEnabled = !noSpeedCheck
+	cfg.PingCheckEnabled = !noPingCheck
+
+	if showVersion {
+		printVersion()
+		return
+	}
+
+	if printSchema {
+		os.Stdout.Write(utils.TestResultsSchema())
+		return
+	}
+
 	log.SetFlags(log.LstdFlags | log.Lmicroseconds)
+	debugEnabled = cfg.Debug
 
-	// Initialize configuration with defaults
-	cfg := config.New()
+	// Deferred first so it runs last: the exit code is only set once the PID file,
+	// profile and tracing cleanups deferred below have run
+	exitCode := 0
+	defer func() {
+		if exitCode != 0 {
+			os.Exit(exitCode)
+		}
+	}()
+
+	if runDaemon {
+		if os.Getenv(daemonEnv) == "" {
+			if err := daemonize(cfg); err != nil {
+				log.Fatalf("Error starting daemon: %v\n", err)
+			}
+			return
+		}
+		// Running as the daemon child
+		defer os.Remove(cfg.PIDFilePath)
+	}
+
+	if profileKind != "" {
+		stopProfiling, err := startProfiling(profileKind, cfg.ProfilePath)
+		if err != nil {
+			log.Fatalf("Error starting profile: %v\n", err)
+		}
+		defer stopProfiling()
+	}
+
+	ctx := context.Background()
+	shutdownTracing := initTracing(ctx)
+	defer shutdownTracing()
+
+	if _, err := utils.NewResultWriter(cfg.ResultsFormat); err != nil {
+		log.Fatalf("Invalid configuration: %v\n", err)
+	}
+	if err := utils.ValidateOutputFilter(cfg.OutputFilter); err != nil {
+		log.Fatalf("Invalid configuration: %v\n", err)
+	}
+	if err := utils.ValidateHTTPVersion(cfg.HTTPVersion); err != nil {
+		log.Fatalf("Invalid configuration: %v\n", err)
+	}
+
+	// Fail early when the requested interface cannot be used
+	if cfg.LocalInterface != "" && cfg.SourceIP == "" {
+		if _, err := utils.ResolveInterfaceAddr(cfg.LocalInterface); err != nil {
+			log.Fatalf("Invalid configuration: %v\n", err)
+		}
+	}
+
+	if slaReport {
+		runSLAReport(cfg)
+		return
+	}
+
+	if mergePattern != "" {
+		runMergeResults(mergePattern, cfg)
+		return
+	}
+
+	if scheduleAt != "" {
+		if err := waitUntilScheduled(scheduleAt, scheduleTZ); err != nil {
+			log.Fatalf("Invalid schedule: %v\n", err)
+		}
+	}
+
+	if scanHost != "" {
+		runPortScan(scanHost, cfg)
+		return
+	}
 
 	// Parse command-line arguments for custom URLs
 	args := flag.Args()
+	if compareProtocols {
+		runCompareProtocols(ctx, args, cfg)
+		return
+	}
+	if len(args) > 0 && (authCredentials != "" || bearerToken != "") {
+		runAuthTests(args, cfg)
+		return
+	}
 	if len(args) > 0 {
-		runHTTPTests(args, cfg)
+		exitCode = finishRun(runHTTPTests(ctx, args, cfg), cfg)
+		return
+	}
+
+	if diffMode {
+		exitCode = runDiffMode(ctx, cfg)
+		return
+	}
+
+	if cfg.WatchInterval > 0 {
+		exitCode = runWatch(ctx, cfg)
 		return
 	}
 
 	// Run all default tests
-	runAllTests(cfg)
+	exitCode = finishRun(runAllTests(ctx, cfg), cfg)
+}
+
+// finishRun emails an alert when one is configured and returns the process exit code:
+// 1 when fail-fast is enabled and any test failed, 0 otherwise
+func finishRun(results *utils.TestResults, cfg *config.Config) int {
+	if cfg.AlertEmail != "" {
+		if err := utils.SendAlertEmail(cfg, results); err != nil {
+			log.Printf("Error sending alert email: %v\n", err)
+		}
+	}
+
+	if cfg.GrafanaURL != "" {
+		if err := utils.PostGrafanaAnnotations(cfg, results); err != nil {
+			log.Printf("Error posting Grafana annotations: %v\n", err)
+		}
+	}
+
+	if cfg.FailFast && hasFailures(results) {
+		fmt.Fprintln(os.Stderr, "Test failed, stopped early because of --fail-fast")
+		return 1
+	}
+	return 0
+}
+
+// hasFailures reports whether any HTTP, DNS, speed, VPN or ping test in results failed
+func hasFailures(results *utils.TestResults) bool {
+	return len(utils.FailedTests(results)) > 0
+}
+
+// configPathFromArgs returns the value of the --config flag in args, if any
+func configPathFromArgs(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		switch {
+		case arg == "-config" || arg == "--config":
+			if i+1 < len(args) {
+				return args[i+1]
+			}
+		case strings.HasPrefix(arg, "-config="), strings.HasPrefix(arg, "--config="):
+			return arg[strings.Index(arg, "=")+1:]
+		}
+	}
+	return ""
+}
+
+// saveResults saves results in the configured format. When the results path is the
+// default, its extension follows the format so e.g. CSV output is not written to data.json.
+// Only the tests selected by the configured output filter are saved, labelled with the report metadata.
+func saveResults(results *utils.TestResults, cfg *config.Config) (string, error) {
+	path := cfg.ResultsFilePath
+	if path == config.DefaultResultsFilePath {
+		if writer, err := utils.NewResultWriter(cfg.ResultsFormat); err == nil {
+			path = strings.TrimSuffix(path, filepath.Ext(path)) + writer.Extension()
+		}
+	}
+
+	results = utils.FilterResults(results, cfg.OutputFilter, cfg.SlowThreshold)
+	results.Metadata = &utils.Metadata{
+		Title:       cfg.ReportTitle,
+		Description: cfg.ReportDescription,
+		GeneratedBy: config.UserAgentProduct,
+		Version:     buildVersion(),
+	}
+	err := utils.SaveResultsWithOptions(results, path, utils.SaveOptions{
+		Format:          cfg.ResultsFormat,
+		FilePermissions: config.FilePermissions,
+		RotateSize:      cfg.ResultsRotateSize,
+		RotateCount:     cfg.ResultsRotateCount,
+		MaxFileSize:     cfg.MaxResultFileSize,
+		TrimOnOversize:  cfg.TrimOnOversize,
+	})
+	// Only results saved locally are uploaded
+	if err == nil {
+		uploadResults(results, path, cfg)
+	}
+	return path, err
+}
+
+// uploadResults uploads results to the configured S3 and GCS buckets. Upload failures are
+// logged rather than returned so they never hide the local results file.
+func uploadResults(results *utils.TestResults, path string, cfg *config.Config) {
+	if cfg.S3Bucket != "" {
+		key := cfg.S3Key
+		if key == "" {
+			key = filepath.Base(path)
+		}
+		if err := utils.NewS3Uploader(cfg.S3Bucket, key).Upload(context.Background(), results); err != nil {
+			log.Printf("Error uploading results to s3://%s/%s: %v\n", cfg.S3Bucket, key, err)
+		} else {
+			fmt.Printf("Results uploaded to s3://%s/%s\n", cfg.S3Bucket, key)
+		}
+	}
+
+	if cfg.GCSBucket != "" {
+		object := cfg.GCSObject
+		if object == "" {
+			object = filepath.Base(path)
+		}
+		if err := utils.NewGCSUploader(cfg.GCSBucket, object).Upload(context.Background(), results); err != nil {
+			log.Printf("Error uploading results to gs://%s/%s: %v\n", cfg.GCSBucket, object, err)
+		} else {
+			fmt.Printf("Results uploaded to gs://%s/%s\n", cfg.GCSBucket, object)
+		}
+	}
+}
+
+// runDiffMode runs all tests and compares them against the baseline file.
+// It returns exit code 1 when any metric regressed beyond the configured tolerance, 0 otherwise.
+func runDiffMode(ctx context.Context, cfg *config.Config) int {
+	// LoadResults treats a missing file as empty results, which would hide every regression
+	if _, err := os.Stat(baselinePath); err != nil {
+		log.Fatalf("Error loading baseline: %v\n", err)
+	}
+
+	baseline, err := utils.LoadResults(baselinePath)
+	if err != nil {
+		log.Fatalf("Error loading baseline: %v\n", err)
+	}
+
+	// Never overwrite the baseline with the current run
+	if cfg.ResultsFilePath == baselinePath {
+		cfg.ResultsFilePath = ""
+	}
+
+	current := runAllTests(ctx, cfg)
+
+	regressions := 0
+	for _, delta := range utils.DiffResults(baseline, current) {
+		if !delta.Regressed(cfg.RegressionTolerance) {
+			continue
+		}
+		regressions++
+		fmt.Fprintf(os.Stderr, "REGRESSION %s: baseline %.2f, current %.2f (%+.1f%%)\n",
+			delta.Metric, delta.Baseline, delta.Current, delta.ChangePct)
+	}
+
+	if regressions > 0 {
+		fmt.Fprintf(os.Stderr, "%d metric(s) regressed beyond %.1f%% tolerance\n", regressions, cfg.RegressionTolerance)
+		return 1
+	}
+
+	fmt.Println("No regressions against baseline", baselinePath)
+	return 0
+}
+
+// buildVersion returns the module version from the build info, or "development build"
+func buildVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok || info.Main.Version == "" || info.Main.Version == "(devel)" {
+		return "development build"
+	}
+	return info.Main.Version
 }
 
-// runHTTPTests runs HTTP tests on the provided URLs
-func runHTTPTests(urls []string, cfg *config.Config) {
+// printVersion prints the module version, Go toolchain and VCS revision from the build info
+func printVersion() {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		fmt.Println("development build")
+		return
+	}
+
+	fmt.Println("Version:", buildVersion())
+	fmt.Println("Go:", info.GoVersion)
+
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			fmt.Println("Commit:", setting.Value)
+		case "vcs.modified":
+			if setting.Value == "true" {
+				fmt.Println("Modified: true")
+			}
+		}
+	}
+}
+
+// runSLAReport generates an SLA compliance report from the stored results history
+func runSLAReport(cfg *config.Config) {
+	history, err := utils.LoadResultsHistory(cfg.ResultsFilePath)
+	if err != nil {
+		log.Fatalf("Error loading results history: %v\n", err)
+	}
+
+	reports := utils.GenerateSLAReport(history, config.SLATarget{UptimePct: slaTargetPct})
+	for _, r := range reports {
+		fmt.Printf("%s: %.3f%% uptime (target %.3f%%), %d outages in %d runs, compliant: %t\n",
+			r.URL, r.ActualPct, r.TargetPct, r.OutageCount, r.TotalRuns, r.Compliant)
+	}
+
+	if err := utils.SaveSLAReport(reports, slaReportPath, config.FilePermissions); err != nil {
+		log.Fatalf("Error saving SLA report: %v\n", err)
+	}
+	fmt.Printf("SLA report saved to %s\n", slaReportPath)
+}
+
+// runValidateConfig prints every invalid config value and exits with code 1 if there are any.
+// Problems found while loading the config file are reported, otherwise the final config is validated.
+func runValidateConfig(cfg *config.Config, configErr error) {
+	err := configErr
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err == nil {
+		fmt.Println("Config is valid")
+		return
+	}
+
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+	for _, e := range errs {
+		fmt.Println(e)
+	}
+	os.Exit(1)
+}
+
+// runMergeResults merges the results files matching pattern and saves the merged report.
+// Files that cannot be loaded are reported and left out.
+func runMergeResults(pattern string, cfg *config.Config) {
+	runs, err := utils.ParseTestResultsFromMultipleFiles([]string{pattern})
+	if err != nil {
+		log.Printf("Some results files were skipped:\n%v\n", err)
+	}
+	if len(runs) == 0 {
+		log.Fatalf("No results files to merge for %s\n", pattern)
+	}
+
+	merged := utils.MergeResults(runs)
+	utils.PrintSummaryTable(os.Stdout, merged)
+
+	path, err := saveResults(merged, cfg)
+	if err != nil {
+		log.Fatalf("Error saving merged results: %v\n", err)
+	}
+	fmt.Printf("Merged %d results files into %s\n", len(runs), path)
+}
+
+// runPortScan scans the --ports of host and saves the result
+func runPortScan(host string, cfg *config.Config) {
+	ports, err := utils.ParsePorts(scanPorts)
+	if err != nil {
+		log.Fatalf("Invalid ports: %v\n", err)
+	}
+
+	result := modules.CheckPortScan(host, ports, cfg)
+	for _, port := range ports {
+		fmt.Printf("%d/tcp\t%s\n", port, result.Results[port])
+	}
+
+	testResults := &utils.TestResults{
+		PortScanTest: result,
+	}
+
+	if _, err := saveResults(testResults, cfg); err != nil {
+		log.Printf("Error saving results: %v\n", err)
+	}
+}
+
+// runAuthTests checks HTTP authentication of each URL with the --auth credentials or the bearer token
+func runAuthTests(urls []string, cfg *config.Config) {
+	if bearerToken != "" {
+		cfg.HTTPBearerToken = bearerToken
+	}
+
+	var username, password string
+	if authCredentials != "" {
+		var ok bool
+		username, password, ok = strings.Cut(authCredentials, ":")
+		if !ok || username == "" {
+			log.Fatalf("Invalid --auth %q, want user:pass\n", authCredentials)
+		}
+	}
+
+	for _, url := range urls {
+		result := modules.CheckHTTPAuth(url, username, password, cfg)
+		if result.Error != "" {
+			fmt.Printf("%s\terror: %s\n", url, result.Error)
+			continue
+		}
+		fmt.Printf("%s\tunauthenticated: %s\tauthenticated: %s\tsuccessful: %t\n",
+			url, result.UnauthStatus, result.AuthStatus, result.AuthSuccessful)
+	}
+}
+
+// runCompareProtocols runs a speed test of each URL forced to HTTP/1.1, HTTP/2 and HTTP/3 and prints a comparison.
+// A row fails when the server does not speak that version; HTTP/3 runs over QUIC and bypasses a SOCKS5 proxy.
+func runCompareProtocols(ctx context.Context, urls []string, cfg *config.Config) {
+	if len(urls) == 0 {
+		log.Fatalln("--compare-protocols needs at least one URL argument")
+	}
+
+	versions := []string{utils.HTTPVersion11, utils.HTTPVersion2, utils.HTTPVersion3}
+
+	var tests []utils.SpeedTest
+	var requested []string
+	for _, url := range urls {
+		for _, version := range versions {
+			c := *cfg
+			c.HTTPVersion = version
+			tests = append(tests, *modules.CheckSpeedContext(ctx, url, &c))
+			requested = append(requested, version)
+		}
+	}
+
+	fmt.Print(utils.NewProtocolComparisonTable(tests, requested))
+}
+
+// runHTTPTests runs HTTP tests on the provided URLs and returns the results
+func runHTTPTests(ctx context.Context, urls []string, cfg *config.Config) *utils.TestResults {
+	ctx, span := otel.Tracer(serviceName).Start(ctx, "runHTTPTests")
+	defer span.End()
+
+	// With --fail-fast the first failed test cancels ctx, so tests not yet started are skipped
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	failFast := func(testErr string) {
+		if cfg.FailFast && testErr != "" {
+			cancel()
+		}
+	}
+
+	// Per-URL options such as https://example.com@eth0:5s override the source address and timeout
+	targets := make([]*utils.URLOptions, len(urls))
+	for i, raw := range urls {
+		opts, err := utils.ParseURLWithTimeout(raw)
+		if err != nil {
+			log.Fatalf("Invalid URL %s: %v\n", raw, err)
+		}
+		targets[i] = opts
+	}
+
 	var wg sync.WaitGroup
-	httpTests := make([]*utils.HTTPTest, len(urls))
+	httpRuns := make([][]utils.HTTPTest, len(urls))
 
-	for i, url := range urls {
+	limiter := utils.NewRateLimiter(cfg.RequestsPerSecond)
+	defer limiter.Stop()
+
+	for i, target := range targets {
 		wg.Add(1)
-		go func(index int, u string) {
+		go func(index int, target *utils.URLOptions) {
 			defer wg.Done()
-			httpTests[index] = modules.TestHTTP(u, cfg)
-		}(i, url)
+			if ctx.Err() != nil {
+				return
+			}
+			// Every repeat takes its own token
+			httpRuns[index] = modules.RepeatHTTPTestLimited(ctx, target.URL, cfg.RepeatCount, cfg.RepeatDelay, limiter, urlConfig(cfg, target))
+			for _, run := range httpRuns[index] {
+				failFast(run.Error)
+			}
+		}(i, target)
 	}
 
 	wg.Wait()
 
-	// Convert to non-pointer slice for storage
-	var results []utils.HTTPTest
-	for _, test := range httpTests {
-		if test != nil {
-			results = append(results, *test)
+	// Save results to file
+	testResults := &utils.TestResults{}
+	for i, runs := range httpRuns {
+		testResults.HTTPTests = append(testResults.HTTPTests, runs...)
+		if cfg.RepeatCount > 1 && len(runs) > 0 {
+			testResults.HTTPStats = append(testResults.HTTPStats, *utils.NewHTTPTestStats(targets[i].URL, runs))
 		}
 	}
 
-	// Save results to file
-	testResults := &utils.TestResults{
-		HTTPTests: results,
+	// Verify certificate pins when any were provided
+	if len(certPins) > 0 {
+		for _, target := range targets {
+			testResults.CertPinTests = append(testResults.CertPinTests, *modules.CheckCertPinning(target.URL, certPins, urlConfig(cfg, target)))
+		}
 	}
 
-	if err := utils.SaveResults(testResults, cfg.ResultsFilePath, config.FilePermissions); err != nil {
+	testResults.Summary = &utils.Summary{
+		FailedTests: len(utils.FailedTests(testResults)),
+	}
+
+	utils.PrintSummaryTable(os.Stdout, testResults)
+
+	if _, err := saveResults(testResults, cfg); err != nil {
 		log.Printf("Error saving results: %v\n", err)
 	}
+
+	return testResults
 }
 
-// runAllTests runs all available tests concurrently
-func runAllTests(cfg *config.Config) {
+// urlConfig returns cfg with the source address and timeout options of target applied
+func urlConfig(cfg *config.Config, target *utils.URLOptions) *config.Config {
+	if target.SourceIP == nil && target.Interface == "" && target.Timeout == 0 {
+		return cfg
+	}
+
+	c := *cfg
+	if target.SourceIP != nil {
+		c.SourceIP = target.SourceIP.String()
+	}
+	if target.Interface != "" {
+		c.SourceIP = ""
+		c.LocalInterface = target.Interface
+	}
+	if target.Timeout > 0 {
+		c.HTTPTimeout = target.Timeout
+	}
+	return &c
+}
+
+// runAllTests runs all available tests concurrently and returns the aggregated results.
+// Results are saved unless cfg.ResultsFilePath is empty.
+func runAllTests(ctx context.Context, cfg *config.Config) *utils.TestResults {
+	ctx, span := otel.Tracer(serviceName).Start(ctx, "runAllTests")
+	defer span.End()
+
+	// With --fail-fast the first failed test cancels ctx, so tests not yet started are skipped
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	failFast := func(testErr string) {
+		if cfg.FailFast && testErr != "" {
+			cancel()
+		}
+	}
+
 	var wg sync.WaitGroup
 
 	// Initialize result containers
 	var (
 		httpTests  []*utils.HTTPTest
+		dnsTests   []*utils.DNSTest
 		speedTests []*utils.SpeedTest
+		httpStats  []utils.HTTPTestStats
+		speedStats []utils.SpeedTestStats
 		vpnTest    *utils.VPNTest
 		pingTest   *utils.PingTest
 		mu         sync.Mutex
@@ -91,36 +785,91 @@ func runAllTests(cfg *config.Config) {
 		"https://google.com",
 	}
 
+	limiter := utils.NewRateLimiter(cfg.RequestsPerSecond)
+	defer limiter.Stop()
+
 	// Run HTTP tests concurrently
 	for _, url := range httpURLs {
 		wg.Add(1)
 		go func(u string) {
 			defer wg.Done()
-			result := modules.TestHTTP(u, cfg)
+			if ctx.Err() != nil {
+				return
+			}
+			// Every repeat takes its own token
+			runs := modules.RepeatHTTPTestLimited(ctx, u, cfg.RepeatCount, cfg.RepeatDelay, limiter, cfg)
 			mu.Lock()
-			httpTests = append(httpTests, result)
+			for i := range runs {
+				httpTests = append(httpTests, &runs[i])
+				failFast(runs[i].Error)
+			}
+			if cfg.RepeatCount > 1 && len(runs) > 0 {
+				httpStats = append(httpStats, *utils.NewHTTPTestStats(u, runs))
+			}
 			mu.Unlock()
 		}(url)
 	}
 
+	// Run DNS tests for each distinct HTTP test host alongside the HTTP tests
+	if cfg.DNSTestEnabled {
+		seen := make(map[string]bool)
+		for _, rawURL := range httpURLs {
+			u, err := neturl.Parse(rawURL)
+			if err != nil || u.Hostname() == "" || seen[u.Hostname()] {
+				continue
+			}
+			seen[u.Hostname()] = true
+
+			wg.Add(1)
+			go func(host string) {
+				defer wg.Done()
+				if ctx.Err() != nil {
+					return
+				}
+				result := modules.CheckDNSContext(ctx, host, cfg)
+				failFast(result.Error)
+				mu.Lock()
+				dnsTests = append(dnsTests, result)
+				mu.Unlock()
+			}(u.Hostname())
+		}
+	}
+
 	// Run speed tests concurrently
-	for _, url := range speedURLs {
-		wg.Add(1)
-		go func(u string) {
-			defer wg.Done()
-			result := modules.CheckSpeed(u, cfg)
-			mu.Lock()
-			speedTests = append(speedTests, result)
-			mu.Unlock()
-		}(url)
+	if cfg.SpeedCheckEnabled {
+		for _, url := range speedURLs {
+			wg.Add(1)
+			go func(u string) {
+				defer wg.Done()
+				if ctx.Err() != nil {
+					return
+				}
+				runs := modules.RepeatSpeedTestContext(ctx, u, cfg.RepeatCount, cfg.RepeatDelay, cfg)
+				mu.Lock()
+				for i := range runs {
+					speedTests = append(speedTests, &runs[i])
+					failFast(runs[i].Error)
+				}
+				if cfg.RepeatCount > 1 && len(runs) > 0 {
+					speedStats = append(speedStats, *utils.NewSpeedTestStats(u, runs))
+				}
+				mu.Unlock()
+			}(url)
+		}
 	}
 
 	// Run VPN check (sequential, as it involves IP detection)
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		vpnTest = modules.CheckVPN("http://checkip.dyndns.org/")
-	}()
+	if cfg.VPNCheckEnabled {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if ctx.Err() != nil {
+				return
+			}
+			vpnTest = modules.CheckVPNContext(ctx, "http://checkip.dyndns.org/", cfg)
+			failFast(vpnTest.Error)
+		}()
+	}
 
 	// Run ping tests concurrently
 	pingDomains := []string{
@@ -128,17 +877,23 @@ func runAllTests(cfg *config.Config) {
 		"www.google.com",
 	}
 
-	for _, domain := range pingDomains {
-		wg.Add(1)
-		go func(d string) {
-			defer wg.Done()
-			result := modules.PingCheck(d, cfg)
-			mu.Lock()
-			if pingTest == nil {
-				pingTest = result
-			}
-			mu.Unlock()
-		}(domain)
+	if cfg.PingCheckEnabled {
+		for _, domain := range pingDomains {
+			wg.Add(1)
+			go func(d string) {
+				defer wg.Done()
+				if ctx.Err() != nil {
+					return
+				}
+				result := modules.PingCheckContext(ctx, d, cfg)
+				failFast(result.Error)
+				mu.Lock()
+				if pingTest == nil {
+					pingTest = result
+				}
+				mu.Unlock()
+			}(domain)
+		}
 	}
 
 	// Wait for all tests to complete
@@ -159,10 +914,20 @@ func runAllTests(cfg *config.Config) {
 		}
 	}
 
+	var dnsTestsValues []utils.DNSTest
+	for _, test := range dnsTests {
+		if test != nil {
+			dnsTestsValues = append(dnsTestsValues, *test)
+		}
+	}
+
 	// Create aggregated results
 	testResults := &utils.TestResults{
 		HTTPTests:  httpTestsValues,
 		SpeedTests: speedTestsValues,
+		DNSTests:   dnsTestsValues,
+		HTTPStats:  httpStats,
+		SpeedStats: speedStats,
 	}
 
 	if vpnTest != nil {
@@ -173,10 +938,32 @@ func runAllTests(cfg *config.Config) {
 		testResults.PingTest = *pingTest
 	}
 
+	testResults.Summary = &utils.Summary{
+		FailedTests: len(utils.FailedTests(testResults)),
+	}
+	if len(speedTestsValues) > 0 {
+		testResults.Summary.SpeedAggregate = utils.AggregateSpeedTests(speedTestsValues)
+	}
+
+	utils.PrintSummaryTable(os.Stdout, testResults)
+
+	if cfg.ResultsFilePath == "" {
+		return testResults
+	}
+
 	// Save all results at once
-	if err := utils.SaveResults(testResults, cfg.ResultsFilePath, config.FilePermissions); err != nil {
+	if path, err := saveResults(testResults, cfg); err != nil {
 		log.Printf("Error saving results: %v\n", err)
 	} else {
-		fmt.Printf("Results saved to %s\n", cfg.ResultsFilePath)
+		fmt.Printf("Results saved to %s\n", path)
+	}
+
+	return testResults
+}
+
+// debugf logs a message only when debug logging is enabled
+func debugf(format string, args ...interface{}) {
+	if debugEnabled {
+		log.Printf("DEBUG: "+format, args...)
 	}
 }
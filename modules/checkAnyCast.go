@@ -0,0 +1,121 @@
+package modules
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+)
+
+// AnyCastResolvers are the public resolvers CheckAnyCast always queries
+var AnyCastResolvers = []string{
+	"8.8.8.8:53",        // Google
+	"1.1.1.1:53",        // Cloudflare
+	"9.9.9.9:53",        // Quad9
+	"208.67.222.222:53", // OpenDNS
+}
+
+// CheckAnyCast resolves a domain through several public resolvers and compares the answers.
+// The resolvers are operated from different networks, so each sees the domain from a different
+// vantage point; when they return different addresses the domain is likely served by anycast
+// or geo-based DNS.
+//
+// Parameters:
+//   - domain: The domain name to resolve
+//   - locations: Additional resolvers to query as "host" or "host:port" (port 53 when omitted)
+//   - cfg: Configuration containing timeout settings
+//
+// Returns:
+//   - *AnyCastTest: Pointer to AnyCastTest struct containing the answer of every resolver and the distinct IPs
+//
+// Example:
+//
+//	cfg := config.New()
+//	result := CheckAnyCast("example.com", []string{"64.6.64.6"}, cfg)
+//	if result.AnyCastDetected {
+//	    log.Println("Resolvers disagree:", result.UniqueIPs)
+//	}
+func CheckAnyCast(domain string, locations []string, cfg *config.Config) *utils.AnyCastTest {
+	result := &utils.AnyCastTest{
+		Domain:          domain,
+		ResolverResults: make(map[string][]string),
+	}
+
+	resolvers := append([]string(nil), AnyCastResolvers...)
+	for _, location := range locations {
+		location = strings.TrimSpace(location)
+		if location == "" {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(location); err != nil {
+			location = net.JoinHostPort(location, "53")
+		}
+		resolvers = append(resolvers, location)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		failures []string
+	)
+
+	for _, address := range resolvers {
+		wg.Add(1)
+		go func(address string) {
+			defer wg.Done()
+
+			ips, err := lookupIPv4(newResolver(address, cfg.DNSTimeout), domain, cfg.DNSTimeout)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failures = append(failures, address+": "+err.Error())
+				log.Printf("DNS lookup via %s failed for %s: %v\n", address, domain, err)
+				return
+			}
+			sort.Strings(ips)
+			result.ResolverResults[address] = ips
+		}(address)
+	}
+	wg.Wait()
+
+	if len(result.ResolverResults) == 0 {
+		sort.Strings(failures)
+		result.Error = utils.NewNetworkError("AnyCast", utils.ErrCodeDNS, "no resolver answered: "+strings.Join(failures, "; "), nil).Error()
+		fmt.Println("------------------------------------------------------------")
+		return result
+	}
+
+	seen := make(map[string]bool)
+	var first []string
+	for _, address := range resolvers {
+		ips, ok := result.ResolverResults[address]
+		if !ok {
+			continue
+		}
+		if first == nil {
+			first = ips
+		} else if !equalStrings(first, ips) {
+			result.AnyCastDetected = true
+		}
+		for _, ip := range ips {
+			if !seen[ip] {
+				seen[ip] = true
+				result.UniqueIPs = append(result.UniqueIPs, ip)
+			}
+		}
+		log.Printf("%s via %s: %v\n", domain, address, ips)
+	}
+	sort.Strings(result.UniqueIPs)
+
+	log.Println("Unique IPs:", result.UniqueIPs)
+	log.Println("Anycast detected:", result.AnyCastDetected)
+	fmt.Println("------------------------------------------------------------")
+
+	return result
+}
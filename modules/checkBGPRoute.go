@@ -0,0 +1,151 @@
+package modules
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+)
+
+// lookingGlassResponse is the subset of the RIPE Stat looking glass response used by CheckBGPRoute
+type lookingGlassResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		RRCs []struct {
+			RRC      string `json:"rrc"`
+			Location string `json:"location"`
+			Peers    []struct {
+				ASPath string `json:"as_path"`
+				Prefix string `json:"prefix"`
+			} `json:"peers"`
+		} `json:"rrcs"`
+	} `json:"data"`
+}
+
+// CheckBGPRoute verifies that a prefix is visible in the global routing table with the expected origin AS.
+// It queries the RIPE Stat looking glass, which reports the routes seen by the RIPE RIS route
+// collectors, and marks the prefix as announced when any collector sees a path originated by asn.
+//
+// Parameters:
+//   - prefix: The IP prefix to look up (e.g., "193.0.0.0/21")
+//   - asn: The AS number expected to originate the prefix
+//   - cfg: Configuration containing the API endpoint and timeout settings
+//
+// Returns:
+//   - *BGPRouteTest: Pointer to BGPRouteTest struct containing the observing collectors, an AS path and any errors
+//
+// Example:
+//
+//	cfg := config.New()
+//	result := CheckBGPRoute("193.0.0.0/21", 3333, cfg)
+//	if !result.Announced {
+//	    log.Println("Prefix not visible from AS3333")
+//	}
+func CheckBGPRoute(prefix string, asn int, cfg *config.Config) *utils.BGPRouteTest {
+	result := &utils.BGPRouteTest{
+		Prefix:      prefix,
+		ExpectedASN: asn,
+	}
+
+	endpoint, err := url.Parse(cfg.BGPAPIEndpoint)
+	if err != nil {
+		result.Error = utils.NewValidationError("BGP", utils.ErrCodeValidation, "invalid BGP API endpoint: "+err.Error()).Error()
+		log.Println("Invalid BGP API endpoint:", cfg.BGPAPIEndpoint, err)
+		return result
+	}
+	query := endpoint.Query()
+	query.Set("resource", prefix)
+	endpoint.RawQuery = query.Encode()
+
+	transport, err := newTransport(cfg)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error creating transport:", cfg.BGPAPIEndpoint, err)
+		return result
+	}
+
+	client := http.Client{
+		Transport: transport,
+		Timeout:   cfg.HTTPTimeout,
+	}
+
+	req, err := http.NewRequest(http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error creating request:", cfg.BGPAPIEndpoint, err)
+		return result
+	}
+	setUserAgent(req, cfg)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error querying BGP API:", cfg.BGPAPIEndpoint, err)
+		return result
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		result.Error = utils.NewNetworkError("BGP", utils.ErrCodeHTTP, "unexpected status "+resp.Status, nil).Error()
+		log.Println("BGP API returned:", resp.Status)
+		return result
+	}
+
+	var lg lookingGlassResponse
+	if err := json.NewDecoder(resp.Body).Decode(&lg); err != nil {
+		result.Error = utils.NewParseError("BGP", utils.ErrCodeParse, "failed to parse BGP API response", err).Error()
+		log.Println("Error parsing BGP API response:", err)
+		return result
+	}
+
+	for _, rrc := range lg.Data.RRCs {
+		for _, peer := range rrc.Peers {
+			fields := strings.Fields(peer.ASPath)
+			if len(fields) == 0 || fields[len(fields)-1] != strconv.Itoa(asn) {
+				continue
+			}
+			path := parseASPath(peer.ASPath)
+
+			result.Announced = true
+			if result.ASPath == nil {
+				result.ASPath = path
+			}
+
+			router := rrc.RRC
+			if rrc.Location != "" {
+				router += " (" + rrc.Location + ")"
+			}
+			result.ObservingRouters = append(result.ObservingRouters, router)
+			break
+		}
+	}
+
+	log.Println("Prefix:", prefix)
+	log.Println("Expected origin: AS" + strconv.Itoa(asn))
+	log.Println("Announced:", result.Announced)
+	log.Println("Observing collectors:", len(result.ObservingRouters))
+	log.Println("AS path:", result.ASPath)
+	fmt.Println("------------------------------------------------------------")
+
+	return result
+}
+
+// parseASPath converts a space separated AS path into AS numbers.
+// AS sets such as "{64512,64513}" are skipped since they are not a single AS.
+func parseASPath(path string) []int {
+	var asns []int
+	for _, field := range strings.Fields(path) {
+		asn, err := strconv.Atoi(field)
+		if err != nil {
+			continue
+		}
+		asns = append(asns, asn)
+	}
+	return asns
+}
@@ -0,0 +1,66 @@
+package modules
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+)
+
+// CheckBandwidthThrottle runs two speed tests against the same URL separated by a pause
+// and flags throttling when the second download is significantly slower than the first.
+//
+// Parameters:
+//   - url: The URL to download from for both speed tests
+//   - cfg: Configuration containing the gap between runs and the slowdown threshold
+//
+// Returns:
+//   - *ThrottleTest: Pointer to ThrottleTest struct containing both speeds and the detection result
+//
+// Example:
+//
+//	cfg := config.New()
+//	result := CheckBandwidthThrottle("https://example.com/largefile", cfg)
+//	if result.ThrottleDetected {
+//	    log.Printf("Speed dropped by %.1f%%\n", result.DeltaPct)
+//	}
+func CheckBandwidthThrottle(url string, cfg *config.Config) *utils.ThrottleTest {
+	result := &utils.ThrottleTest{
+		URL: url,
+	}
+
+	run1 := CheckSpeed(url, cfg)
+	if run1.Error != "" {
+		result.Error = run1.Error
+		return result
+	}
+	result.Run1Mbps = run1.DownloadMbps
+
+	time.Sleep(cfg.ThrottleTestGap)
+
+	run2 := CheckSpeed(url, cfg)
+	if run2.Error != "" {
+		result.Error = run2.Error
+		return result
+	}
+	result.Run2Mbps = run2.DownloadMbps
+
+	if result.Run1Mbps > 0 {
+		result.DeltaPct = (result.Run1Mbps - result.Run2Mbps) / result.Run1Mbps * 100
+	}
+	result.ThrottleDetected = result.DeltaPct > cfg.ThrottleThresholdPct
+
+	log.Println("URL:", url)
+	log.Printf("Run 1: %.2f Mbps, Run 2: %.2f Mbps, slowdown: %.1f%%\n",
+		result.Run1Mbps, result.Run2Mbps, result.DeltaPct)
+	if result.ThrottleDetected {
+		log.Println("Bandwidth throttling detected.")
+	} else {
+		log.Println("No bandwidth throttling detected.")
+	}
+
+	fmt.Println("------------------------------------------------------------")
+	return result
+}
@@ -0,0 +1,198 @@
+package modules
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	neturl "net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+)
+
+// BufferbloatDownloadPath is appended to a bare host name to build the download that loads the link.
+// It matches the download endpoint of speed.cloudflare.com; pass a full URL for other servers.
+const BufferbloatDownloadPath = "/__down?bytes=100000000"
+
+const (
+	// bufferbloatSamples is the number of TCP connections timed idle and under load
+	bufferbloatSamples = 5
+
+	// bufferbloatRampUp is how long the download runs before loaded RTTs are measured,
+	// giving TCP time to fill the queues along the path
+	bufferbloatRampUp = time.Second
+
+	// bufferbloatInterval separates the loaded RTT samples
+	bufferbloatInterval = 100 * time.Millisecond
+)
+
+// CheckNetworkBufferbloat detects bufferbloat, latency that grows when the link is busy because
+// oversized buffers queue packets. It times TCP connections to host while idle, then starts a bulk
+// download from host and times connections again while the download runs. The medians of both
+// series give BaselineRTT and LoadedRTT, and their difference is rated by utils.RateBufferbloat.
+//
+// host is either a host name, downloaded from over HTTPS with BufferbloatDownloadPath, or a URL
+// of a large file. Connections are timed to the host and port of the download.
+//
+// Parameters:
+//   - host: The host name or download URL (e.g., "speed.cloudflare.com")
+//   - cfg: Configuration containing connect timeout and speed test timeout settings
+//
+// Returns:
+//   - *BufferbloatTest: Pointer to BufferbloatTest struct containing the RTTs, the severity and any errors
+//
+// Example:
+//
+//	cfg := config.New()
+//	result := CheckNetworkBufferbloat("speed.cloudflare.com", cfg)
+//	if result.BufferbloatSeverity != utils.BufferbloatNone {
+//	    log.Println("Latency under load grows by", result.Increase)
+//	}
+func CheckNetworkBufferbloat(host string, cfg *config.Config) *utils.BufferbloatTest {
+	result := &utils.BufferbloatTest{
+		Host: host,
+	}
+
+	downloadURL, addr, err := bufferbloatTarget(host)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error parsing bufferbloat target:", host, err)
+		return result
+	}
+
+	dialer, err := newDialer(cfg)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error creating dialer:", err)
+		return result
+	}
+	dialer.Timeout = cfg.HTTPConnectTimeout
+
+	baseline, err := sampleConnectRTT(dialer, addr, 0)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error measuring baseline RTT:", addr, err)
+		return result
+	}
+	result.BaselineRTT = baseline
+
+	transport, err := newHTTPRoundTripper(cfg)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error creating transport:", downloadURL, err)
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.SpeedTestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, downloadURL, nil)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error creating request:", downloadURL, err)
+		return result
+	}
+	setUserAgent(req, cfg)
+
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error starting download:", downloadURL, err)
+		return result
+	}
+	defer resp.Body.Close()
+
+	// Drain the body in the background; done is closed when the download ends
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		io.Copy(io.Discard, resp.Body)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(bufferbloatRampUp):
+	}
+
+	loaded, err := sampleConnectRTT(dialer, addr, bufferbloatInterval)
+	select {
+	case <-done:
+		log.Println("Warning: download finished before the loaded RTT was measured:", downloadURL)
+	default:
+	}
+	cancel()
+	<-done
+
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error measuring loaded RTT:", addr, err)
+		return result
+	}
+	result.LoadedRTT = loaded
+	result.Increase = loaded - baseline
+	result.BufferbloatSeverity = utils.RateBufferbloat(result.Increase)
+
+	log.Println("Host:", host)
+	log.Println("Baseline RTT:", result.BaselineRTT)
+	log.Println("Loaded RTT:", result.LoadedRTT)
+	log.Println("Increase:", result.Increase, "bufferbloat:", result.BufferbloatSeverity)
+	fmt.Println("------------------------------------------------------------")
+
+	return result
+}
+
+// bufferbloatTarget returns the download URL for host and the "host:port" address its RTT is measured to
+func bufferbloatTarget(host string) (string, string, error) {
+	downloadURL := host
+	if !strings.Contains(host, "://") {
+		downloadURL = "https://" + host + BufferbloatDownloadPath
+	}
+
+	u, err := neturl.Parse(downloadURL)
+	if err != nil || u.Hostname() == "" {
+		return "", "", utils.NewValidationError("Bufferbloat", utils.ErrCodeValidation, "invalid host or URL: "+host)
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "443"
+		if u.Scheme == "http" {
+			port = "80"
+		}
+	}
+	return downloadURL, net.JoinHostPort(u.Hostname(), port), nil
+}
+
+// sampleConnectRTT times bufferbloatSamples TCP connections to addr, pausing interval between
+// them, and returns the median. Failed connections are skipped; it fails when all of them fail.
+func sampleConnectRTT(dialer *net.Dialer, addr string, interval time.Duration) (time.Duration, error) {
+	var rtts []time.Duration
+	var lastErr error
+	for i := 0; i < bufferbloatSamples; i++ {
+		if i > 0 {
+			time.Sleep(interval)
+		}
+
+		start := time.Now()
+		conn, err := dialer.Dial("tcp", addr)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		rtts = append(rtts, time.Since(start))
+		conn.Close()
+	}
+
+	if len(rtts) == 0 {
+		return 0, utils.NewNetworkError("Bufferbloat", utils.ErrCodeNetwork, "cannot connect to "+addr, lastErr)
+	}
+
+	sort.Slice(rtts, func(i, j int) bool { return rtts[i] < rtts[j] })
+	return rtts[len(rtts)/2], nil
+}
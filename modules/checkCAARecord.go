@@ -0,0 +1,189 @@
+package modules
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"strings"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	// dnsTypeCAA is the CAA resource record type (RFC 8659), not defined by dnsmessage
+	dnsTypeCAA dnsmessage.Type = 257
+
+	// caaFlagCritical is the issuer critical flag of a CAA record
+	caaFlagCritical = 0x80
+)
+
+// CheckCAARecord looks up the CAA records restricting which certificate authorities may issue for a domain.
+// Like a CA would, it climbs towards the root until a domain with CAA records is found (RFC 8659 section 3).
+// Queries go to cfg.DNSResolver, or ReferenceDNSResolver when the system resolver is configured,
+// since the standard library resolver cannot look up CAA records.
+//
+// Parameters:
+//   - domain: The domain name to check (e.g., "example.com")
+//   - cfg: Configuration containing the resolver and timeout settings
+//
+// Returns:
+//   - *CAATest: Pointer to CAATest struct containing the records, the authorized CAs and any errors
+//
+// Example:
+//
+//	cfg := config.New()
+//	result := CheckCAARecord("example.com", cfg)
+//	if result.Error == "" {
+//	    log.Println("Authorized CAs:", result.IssueAllowed)
+//	}
+func CheckCAARecord(domain string, cfg *config.Config) *utils.CAATest {
+	result := &utils.CAATest{
+		Domain: domain,
+	}
+
+	server := cfg.DNSResolver
+	if server == "" {
+		server = ReferenceDNSResolver
+	}
+
+	dialer, err := newUDPDialer(cfg)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error creating dialer:", server, err)
+		return result
+	}
+
+	// An empty record set means any CA may issue, so keep climbing until records are found
+	name := strings.TrimSuffix(domain, ".")
+	for name != "" {
+		query, err := buildDNSQuery(uint16(rand.Intn(1<<16)), name, dnsTypeCAA)
+		if err != nil {
+			result.Error = err.Error()
+			log.Println("Error building DNS query:", name, err)
+			return result
+		}
+
+		response, err := exchangeDNS(dialer, server, query, cfg.DNSTimeout)
+		if err != nil {
+			result.Error = err.Error()
+			log.Println("Error querying CAA records:", name, err)
+			return result
+		}
+
+		records, err := parseCAARecords(response)
+		if err != nil {
+			result.Error = err.Error()
+			log.Println("Error parsing CAA records:", name, err)
+			return result
+		}
+
+		if len(records) > 0 {
+			result.Records = records
+			if name != strings.TrimSuffix(domain, ".") {
+				log.Println("CAA records inherited from:", name)
+			}
+			break
+		}
+
+		_, parent, _ := strings.Cut(name, ".")
+		name = parent
+	}
+
+	hasIssueWild := false
+	for _, r := range result.Records {
+		switch r.Tag {
+		case "issue":
+			if ca := caaIssuer(r.Value); ca != "" {
+				result.IssueAllowed = append(result.IssueAllowed, ca)
+			}
+		case "issuewild":
+			hasIssueWild = true
+			if ca := caaIssuer(r.Value); ca != "" {
+				result.IssueWildcardAllowed = append(result.IssueWildcardAllowed, ca)
+			}
+		}
+	}
+
+	// Without issuewild records the issue records also govern wildcard certificates
+	if !hasIssueWild {
+		result.IssueWildcardAllowed = result.IssueAllowed
+	}
+
+	log.Println("Domain:", domain)
+	if len(result.Records) == 0 {
+		log.Println("No CAA records, any CA may issue certificates")
+	}
+	for _, r := range result.Records {
+		log.Printf("CAA %s %q critical=%t\n", r.Tag, r.Value, r.Critical)
+	}
+	log.Println("Authorized CAs:", result.IssueAllowed)
+	log.Println("Authorized wildcard CAs:", result.IssueWildcardAllowed)
+	fmt.Println("------------------------------------------------------------")
+
+	return result
+}
+
+// parseCAARecords extracts the CAA records from the answer section of a wire format response
+func parseCAARecords(msg []byte) ([]utils.CAARecord, error) {
+	var parser dnsmessage.Parser
+	header, err := parser.Start(msg)
+	if err != nil {
+		return nil, utils.NewParseError("DNS", utils.ErrCodeParse, "failed to parse DNS response header", err)
+	}
+
+	// NXDOMAIN leaves the record set empty so the caller can climb to the parent domain
+	if header.RCode != dnsmessage.RCodeSuccess && header.RCode != dnsmessage.RCodeNameError {
+		return nil, utils.NewNetworkError("DNS", utils.ErrCodeDNS, "DNS server returned "+header.RCode.String(), nil)
+	}
+
+	if err := parser.SkipAllQuestions(); err != nil {
+		return nil, utils.NewParseError("DNS", utils.ErrCodeParse, "failed to skip DNS questions", err)
+	}
+
+	var records []utils.CAARecord
+	for {
+		h, err := parser.AnswerHeader()
+		if err == dnsmessage.ErrSectionDone {
+			break
+		}
+		if err != nil {
+			return nil, utils.NewParseError("DNS", utils.ErrCodeParse, "failed to parse DNS answer", err)
+		}
+
+		if h.Type != dnsTypeCAA {
+			if err := parser.SkipAnswer(); err != nil {
+				return nil, utils.NewParseError("DNS", utils.ErrCodeParse, "failed to skip DNS answer", err)
+			}
+			continue
+		}
+
+		r, err := parser.UnknownResource()
+		if err != nil {
+			return nil, utils.NewParseError("DNS", utils.ErrCodeParse, "failed to parse CAA record", err)
+		}
+
+		// RDATA is a flags byte, a tag length byte, the tag and the value
+		data := r.Data
+		if len(data) < 2 || len(data) < 2+int(data[1]) {
+			return nil, utils.NewParseError("DNS", utils.ErrCodeParse, "malformed CAA record", nil)
+		}
+		tagEnd := 2 + int(data[1])
+
+		records = append(records, utils.CAARecord{
+			Tag:      strings.ToLower(string(data[2:tagEnd])),
+			Value:    string(data[tagEnd:]),
+			Critical: data[0]&caaFlagCritical != 0,
+		})
+	}
+
+	return records, nil
+}
+
+// caaIssuer returns the CA domain of an issue or issuewild value, dropping any parameters.
+// An empty result means the record forbids issuance.
+func caaIssuer(value string) string {
+	issuer, _, _ := strings.Cut(value, ";")
+	return strings.TrimSpace(issuer)
+}
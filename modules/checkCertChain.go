@@ -0,0 +1,134 @@
+package modules
+
+import (
+	"crypto/x509"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+)
+
+// CheckHTTPSCertificateChain inspects the certificate chain an HTTPS server presents.
+// The handshake itself skips verification so broken chains can still be examined; the chain is
+// then verified against the system trust store with the presented intermediates, like
+// resp.TLS.VerifiedChains would be. The chain is complete when every presented certificate is
+// signed by the next one and it leads to a trusted root.
+//
+// Parameters:
+//   - url: The HTTPS URL to check
+//   - cfg: Configuration containing timeout settings
+//
+// Returns:
+//   - *CertChainTest: Pointer to CertChainTest struct containing every certificate of the chain and whether it is complete
+//
+// Example:
+//
+//	cfg := config.New()
+//	result := CheckHTTPSCertificateChain("https://example.com", cfg)
+//	if !result.ChainComplete {
+//	    log.Println("Incomplete certificate chain:", result.Error)
+//	}
+func CheckHTTPSCertificateChain(url string, cfg *config.Config) *utils.CertChainTest {
+	result := &utils.CertChainTest{
+		URL: url,
+	}
+
+	transport, err := newTransport(cfg)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error creating transport:", url, err)
+		return result
+	}
+	transport.TLSClientConfig.InsecureSkipVerify = true
+
+	client := http.Client{
+		Transport: transport,
+		Timeout:   cfg.HTTPTimeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error creating request:", url, err)
+		return result
+	}
+	setUserAgent(req, cfg)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error sending request:", url, err)
+		return result
+	}
+	resp.Body.Close()
+
+	if resp.TLS == nil || len(resp.TLS.PeerCertificates) == 0 {
+		result.Error = utils.NewValidationError("CertChain", utils.ErrCodeValidation, "no TLS certificates presented").Error()
+		log.Println("No TLS certificates presented by:", url)
+		return result
+	}
+
+	presented := resp.TLS.PeerCertificates
+	linked := true
+	for i := 0; i+1 < len(presented); i++ {
+		if err := presented[i].CheckSignatureFrom(presented[i+1]); err != nil {
+			linked = false
+			log.Printf("Certificate %d is not signed by certificate %d: %v\n", i, i+1, err)
+		}
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range presented[1:] {
+		intermediates.AddCert(cert)
+	}
+	chains, verifyErr := presented[0].Verify(x509.VerifyOptions{
+		DNSName:       req.URL.Hostname(),
+		Intermediates: intermediates,
+	})
+
+	// Report the verified chain including its root when there is one, otherwise what was presented
+	chain := presented
+	if verifyErr == nil && len(chains) > 0 {
+		chain = chains[0]
+	}
+
+	now := time.Now()
+	for _, cert := range chain {
+		info := utils.CertInfo{
+			Subject:   cert.Subject.String(),
+			Issuer:    cert.Issuer.String(),
+			NotBefore: cert.NotBefore,
+			NotAfter:  cert.NotAfter,
+			IsCA:      cert.IsCA,
+			Expired:   now.After(cert.NotAfter),
+		}
+		if info.Expired {
+			result.AnyExpired = true
+		}
+		result.Certificates = append(result.Certificates, info)
+	}
+	result.ChainLength = len(chain)
+	result.ChainComplete = linked && verifyErr == nil
+
+	switch {
+	case verifyErr != nil:
+		result.Error = utils.NewValidationError("CertChain", utils.ErrCodeValidation, "chain verification failed: "+verifyErr.Error()).Error()
+	case !linked:
+		result.Error = utils.NewValidationError("CertChain", utils.ErrCodeValidation, "presented certificates are out of order or unrelated").Error()
+	}
+
+	log.Println("URL:", url)
+	for i, info := range result.Certificates {
+		log.Printf("Certificate %d: %s (issuer %s, expires %s)\n", i, info.Subject, info.Issuer, info.NotAfter.Format(time.RFC3339))
+	}
+	log.Println("Chain complete:", result.ChainComplete, "any expired:", result.AnyExpired)
+	fmt.Println("------------------------------------------------------------")
+
+	return result
+}
@@ -0,0 +1,132 @@
+package modules
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+)
+
+const (
+	// PinPrefix is the prefix used for SHA-256 SPKI pins (e.g., "SHA256:base64...")
+	PinPrefix = "SHA256:"
+)
+
+// CheckCertPinning verifies the TLS certificate chain of the given URL against a list of SPKI pins.
+// Each pin is the base64 encoded SHA-256 hash of a certificate's SubjectPublicKeyInfo,
+// as used by HPKP's pin-sha256 directive, optionally prefixed with "SHA256:".
+//
+// Parameters:
+//   - url: The HTTPS URL whose certificate chain should be checked
+//   - pins: Accepted pins; the check passes when any certificate in the chain matches one
+//   - cfg: Configuration containing timeout settings
+//
+// Returns:
+//   - *CertPinTest: Pointer to CertPinTest struct containing the chain fingerprints and match status
+//
+// Example:
+//
+//	cfg := config.New()
+//	result := CheckCertPinning("https://example.com", []string{"SHA256:r/mIkG3eEpVdm+u/ko/cwxzOMo1bk4TyHIlByibiA5E="}, cfg)
+//	if !result.PinMatched {
+//	    log.Println("Pin validation failed:", result.Error)
+//	}
+func CheckCertPinning(url string, pins []string, cfg *config.Config) *utils.CertPinTest {
+	result := &utils.CertPinTest{
+		URL: url,
+	}
+
+	if len(pins) == 0 {
+		result.Error = utils.NewValidationError("CertPin", utils.ErrCodeValidation, "no pins provided").Error()
+		log.Println("No pins provided for:", url)
+		return result
+	}
+
+	// Connect like the HTTP tests do so the pins are checked over the same path
+	transport, err := newTransport(cfg)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error creating transport:", url, err)
+		return result
+	}
+
+	client := http.Client{
+		Transport: transport,
+		Timeout:   cfg.HTTPTimeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error creating request:", url, err)
+		return result
+	}
+	setUserAgent(req, cfg)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error sending request:", url, err)
+		return result
+	}
+	defer resp.Body.Close()
+
+	if resp.TLS == nil || len(resp.TLS.PeerCertificates) == 0 {
+		result.Error = utils.NewValidationError("CertPin", utils.ErrCodeValidation, "no TLS certificates presented").Error()
+		log.Println("No TLS certificates presented by:", url)
+		return result
+	}
+
+	// Normalize the accepted pins to bare base64 hashes
+	accepted := make(map[string]string, len(pins))
+	for _, pin := range pins {
+		accepted[normalizePin(pin)] = pin
+	}
+
+	for _, cert := range resp.TLS.PeerCertificates {
+		sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+		fingerprint := base64.StdEncoding.EncodeToString(sum[:])
+		result.CertFingerprints = append(result.CertFingerprints, PinPrefix+fingerprint)
+
+		if pin, ok := accepted[fingerprint]; ok && !result.PinMatched {
+			result.PinMatched = true
+			result.MatchedPin = pin
+		}
+	}
+
+	log.Println("URL:", url)
+	for _, fingerprint := range result.CertFingerprints {
+		log.Println("Certificate fingerprint:", fingerprint)
+	}
+
+	if !result.PinMatched {
+		result.Error = utils.NewValidationError("CertPin", utils.ErrCodeValidation,
+			fmt.Sprintf("none of the %d certificates matched the provided pins", len(result.CertFingerprints))).Error()
+		log.Println("Certificate pin mismatch:", url)
+	} else {
+		log.Println("Certificate pin matched:", result.MatchedPin)
+	}
+
+	fmt.Println("------------------------------------------------------------")
+	return result
+}
+
+// normalizePin strips known pin prefixes and surrounding quotes
+func normalizePin(pin string) string {
+	pin = strings.TrimSpace(pin)
+	for _, prefix := range []string{PinPrefix, "sha256/", "pin-sha256="} {
+		if len(pin) >= len(prefix) && strings.EqualFold(pin[:len(prefix)], prefix) {
+			pin = pin[len(prefix):]
+			break
+		}
+	}
+	return strings.Trim(pin, `"`)
+}
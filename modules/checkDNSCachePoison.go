@@ -0,0 +1,90 @@
+package modules
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+)
+
+const (
+	// ReferenceDNSResolver is the resolver answers are compared against
+	ReferenceDNSResolver = "8.8.8.8:53"
+)
+
+// CheckDNSCachePoison resolves the A records of a domain through two resolvers and compares the answers.
+// Differing answers may indicate DNS cache poisoning, DNS hijacking, or split-horizon DNS.
+//
+// Parameters:
+//   - domain: The domain name to resolve
+//   - cfg: Configuration containing the primary resolver and timeout settings
+//
+// Returns:
+//   - *DNSCachePoisonTest: Pointer to DNSCachePoisonTest struct containing both answers and whether they match
+//
+// Example:
+//
+//	cfg := config.New()
+//	result := CheckDNSCachePoison("example.com", cfg)
+//	if result.Error == "" && !result.Consistent {
+//	    log.Println("Resolvers disagree:", result.IPs1, result.IPs2)
+//	}
+func CheckDNSCachePoison(domain string, cfg *config.Config) *utils.DNSCachePoisonTest {
+	result := &utils.DNSCachePoisonTest{
+		Domain:    domain,
+		Resolver1: cfg.DNSResolver,
+		Resolver2: ReferenceDNSResolver,
+	}
+
+	if result.Resolver1 == "" {
+		result.Resolver1 = "system"
+	}
+
+	ips1, err := lookupIPv4(newResolver(cfg.DNSResolver, cfg.DNSTimeout), domain, cfg.DNSTimeout)
+	if err != nil {
+		result.Error = err.Error()
+		log.Printf("DNS lookup via %s failed for %s: %v\n", result.Resolver1, domain, err)
+		fmt.Println("------------------------------------------------------------")
+		return result
+	}
+
+	ips2, err := lookupIPv4(newResolver(ReferenceDNSResolver, cfg.DNSTimeout), domain, cfg.DNSTimeout)
+	if err != nil {
+		result.Error = err.Error()
+		log.Printf("DNS lookup via %s failed for %s: %v\n", result.Resolver2, domain, err)
+		fmt.Println("------------------------------------------------------------")
+		return result
+	}
+
+	sort.Strings(ips1)
+	sort.Strings(ips2)
+	result.IPs1 = ips1
+	result.IPs2 = ips2
+	result.Consistent = equalStrings(ips1, ips2)
+
+	log.Printf("%s via %s: %v\n", domain, result.Resolver1, ips1)
+	log.Printf("%s via %s: %v\n", domain, result.Resolver2, ips2)
+	if result.Consistent {
+		log.Println("DNS answers are consistent.")
+	} else {
+		log.Println("DNS answers differ, possible cache poisoning or split-horizon DNS.")
+	}
+
+	fmt.Println("------------------------------------------------------------")
+	return result
+}
+
+// equalStrings reports whether two sorted slices hold the same values
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
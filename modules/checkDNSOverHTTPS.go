@@ -0,0 +1,123 @@
+package modules
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	// DoHContentType is the media type of DNS wire format messages (RFC 8484)
+	DoHContentType = "application/dns-message"
+
+	// DefaultDoHURL is a public DNS-over-HTTPS endpoint
+	DefaultDoHURL = "https://cloudflare-dns.com/dns-query"
+)
+
+// CheckDNSOverHTTPS resolves a domain through a DNS-over-HTTPS server using the RFC 8484 GET method.
+// The query is sent as a base64url encoded wire format message and the binary response is parsed for A records.
+//
+// Parameters:
+//   - domain: The domain name to resolve
+//   - dohURL: The DoH endpoint (e.g., "https://cloudflare-dns.com/dns-query")
+//   - cfg: Configuration containing timeout settings
+//
+// Returns:
+//   - *DoHTest: Pointer to DoHTest struct containing resolved IPs, round-trip time and any errors
+//
+// Example:
+//
+//	cfg := config.New()
+//	result := CheckDNSOverHTTPS("example.com", DefaultDoHURL, cfg)
+//	if result.Error == "" {
+//	    log.Println("Resolved:", result.ResolvedIPs)
+//	}
+func CheckDNSOverHTTPS(domain string, dohURL string, cfg *config.Config) *utils.DoHTest {
+	result := &utils.DoHTest{
+		Domain: domain,
+		DoHURL: dohURL,
+	}
+
+	// RFC 8484 recommends an ID of 0 for cache friendliness
+	query, err := buildDNSQuery(0, domain, dnsmessage.TypeA)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error building DNS query:", domain, err)
+		return result
+	}
+
+	separator := "?"
+	if strings.Contains(dohURL, "?") {
+		separator = "&"
+	}
+	queryURL := dohURL + separator + "dns=" + base64.RawURLEncoding.EncodeToString(query)
+
+	req, err := http.NewRequest(http.MethodGet, queryURL, nil)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error creating request:", dohURL, err)
+		return result
+	}
+	req.Header.Set("Accept", DoHContentType)
+	setUserAgent(req, cfg)
+
+	transport, err := newTransport(cfg)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error creating transport:", dohURL, err)
+		return result
+	}
+
+	client := http.Client{
+		Transport: transport,
+		Timeout:   cfg.HTTPTimeout,
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error sending DoH query:", dohURL, err)
+		return result
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	result.RTT = time.Since(start)
+	result.StatusCode = resp.StatusCode
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error reading DoH response:", dohURL, err)
+		return result
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		result.Error = utils.NewNetworkError("DoH", utils.ErrCodeHTTP, "unexpected status "+resp.Status, nil).Error()
+		log.Println("DoH server returned:", resp.Status)
+		return result
+	}
+
+	ips, err := parseDNSAddrs(body)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error parsing DoH response:", dohURL, err)
+		return result
+	}
+	result.ResolvedIPs = ips
+
+	log.Println("Domain:", domain)
+	log.Println("DoH server:", dohURL)
+	log.Println("Resolved IPs:", ips)
+	log.Println("RTT:", result.RTT)
+	fmt.Println("------------------------------------------------------------")
+
+	return result
+}
@@ -0,0 +1,134 @@
+package modules
+
+import (
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	// DoTPort is the TCP port of DNS-over-TLS (RFC 7858)
+	DoTPort = "853"
+)
+
+// CheckDNSOverTLS resolves a domain through a DNS-over-TLS server.
+// The query is sent in the length-prefixed TCP wire format of RFC 7858 and the whole
+// exchange is bounded by cfg.PingTimeout.
+//
+// Parameters:
+//   - domain: The domain name to resolve
+//   - dotServer: The DoT server host name or IP (e.g., "1.1.1.1" or "dns.google"); port 853 is used unless given
+//   - cfg: Configuration containing timeout and TLS settings
+//
+// Returns:
+//   - *DoTTest: Pointer to DoTTest struct containing resolved IPs, handshake and query timings and any errors
+//
+// Example:
+//
+//	cfg := config.New()
+//	result := CheckDNSOverTLS("example.com", "1.1.1.1", cfg)
+//	if result.Error == "" {
+//	    log.Println("Resolved:", result.ResolvedIPs, "over", result.TLSVersion)
+//	}
+func CheckDNSOverTLS(domain string, dotServer string, cfg *config.Config) *utils.DoTTest {
+	result := &utils.DoTTest{
+		Domain:    domain,
+		DoTServer: dotServer,
+	}
+
+	host, port, err := net.SplitHostPort(dotServer)
+	if err != nil {
+		host, port = dotServer, DoTPort
+	}
+
+	query, err := buildDNSQuery(uint16(rand.Intn(1<<16)), domain, dnsmessage.TypeA)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error building DNS query:", domain, err)
+		return result
+	}
+
+	dialer, err := newDialer(cfg)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error creating dialer:", dotServer, err)
+		return result
+	}
+
+	deadline := time.Now().Add(cfg.PingTimeout)
+	dialer.Deadline = deadline
+
+	start := time.Now()
+	conn, err := tls.DialWithDialer(dialer, "tcp", net.JoinHostPort(host, port), &tls.Config{
+		ServerName: host,
+		MinVersion: cfg.TLSMinVersion,
+		MaxVersion: cfg.TLSMaxVersion,
+	})
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("DoT handshake failed:", dotServer, err)
+		fmt.Println("------------------------------------------------------------")
+		return result
+	}
+	defer conn.Close()
+
+	result.HandshakeTime = time.Since(start)
+	result.TLSVersion = utils.TLSVersionName(conn.ConnectionState().Version)
+
+	if err := conn.SetDeadline(deadline); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	// RFC 7858 messages are prefixed with a two byte length
+	msg := make([]byte, 2+len(query))
+	binary.BigEndian.PutUint16(msg, uint16(len(query)))
+	copy(msg[2:], query)
+
+	queryStart := time.Now()
+	if _, err := conn.Write(msg); err != nil {
+		result.Error = err.Error()
+		log.Println("Error sending DoT query:", dotServer, err)
+		return result
+	}
+
+	var length [2]byte
+	if _, err := io.ReadFull(conn, length[:]); err != nil {
+		result.Error = err.Error()
+		log.Println("Error reading DoT response:", dotServer, err)
+		return result
+	}
+
+	response := make([]byte, binary.BigEndian.Uint16(length[:]))
+	if _, err := io.ReadFull(conn, response); err != nil {
+		result.Error = err.Error()
+		log.Println("Error reading DoT response:", dotServer, err)
+		return result
+	}
+	result.QueryTime = time.Since(queryStart)
+
+	ips, err := parseDNSAddrs(response)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error parsing DoT response:", dotServer, err)
+		return result
+	}
+	result.ResolvedIPs = ips
+
+	log.Println("Domain:", domain)
+	log.Println("DoT server:", dotServer, result.TLSVersion)
+	log.Println("Resolved IPs:", ips)
+	log.Println("Handshake time:", result.HandshakeTime, "query time:", result.QueryTime)
+	fmt.Println("------------------------------------------------------------")
+
+	return result
+}
@@ -0,0 +1,241 @@
+package modules
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// LowTTLThreshold is the record TTL, in seconds, below which CheckDNSTTL warns
+const LowTTLThreshold = 60
+
+// CheckDNSTTL queries the SOA and A records of a domain directly at its authoritative nameserver
+// and reports the TTLs it hands out. Recursive resolvers count TTLs down while records are cached,
+// so only the authoritative answer shows the configured values. The nameserver is found by looking
+// up the NS records of the domain, climbing towards the root until its zone is found.
+//
+// Parameters:
+//   - domain: The domain name to check (e.g., "example.com")
+//   - cfg: Configuration containing the resolver and timeout settings
+//
+// Returns:
+//   - *DNSTTLTest: Pointer to DNSTTLTest struct containing the TTLs and any errors
+//
+// Example:
+//
+//	cfg := config.New()
+//	result := CheckDNSTTL("example.com", cfg)
+//	if result.LowTTLWarning {
+//	    log.Println("Low TTL:", result.RecordTTL)
+//	}
+func CheckDNSTTL(domain string, cfg *config.Config) *utils.DNSTTLTest {
+	result := &utils.DNSTTLTest{
+		Domain: domain,
+	}
+
+	resolver := newResolver(cfg.DNSResolver, cfg.DNSTimeout)
+
+	zone, ns, err := findAuthoritativeNS(resolver, domain, cfg.DNSTimeout)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error finding authoritative nameserver:", domain, err)
+		return result
+	}
+	result.AuthoritativeNS = ns
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.DNSTimeout)
+	addrs, err := resolver.LookupHost(ctx, ns)
+	cancel()
+	if err != nil || len(addrs) == 0 {
+		result.Error = utils.NewNetworkError("DNS", utils.ErrCodeDNS, "cannot resolve nameserver "+ns, err).Error()
+		log.Println("Error resolving nameserver:", ns, err)
+		return result
+	}
+	server := net.JoinHostPort(addrs[0], "53")
+
+	dialer, err := newUDPDialer(cfg)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error creating dialer:", server, err)
+		return result
+	}
+
+	soa, err := queryAuthoritative(dialer, server, zone, dnsmessage.TypeSOA, cfg.DNSTimeout)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error querying SOA record:", zone, err)
+		return result
+	}
+	if !soa.hasSOA {
+		result.Error = utils.NewParseError("DNS", utils.ErrCodeParse, "no SOA record for "+zone, nil).Error()
+		log.Println("Error querying SOA record:", zone, result.Error)
+		return result
+	}
+	result.SOATTL = soa.soaTTL
+
+	// Negative answers are cached for the lower of the SOA TTL and its MINIMUM field (RFC 2308 section 5)
+	result.NegativeTTL = soa.soaTTL
+	if soa.soaMinTTL < result.NegativeTTL {
+		result.NegativeTTL = soa.soaMinTTL
+	}
+
+	start := time.Now()
+	record, err := queryAuthoritative(dialer, server, domain, dnsmessage.TypeA, cfg.DNSTimeout)
+	result.QueryTime = time.Since(start)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error querying A record:", domain, err)
+		return result
+	}
+
+	if record.answered {
+		result.RecordTTL = record.recordTTL
+		result.LowTTLWarning = record.recordTTL < LowTTLThreshold
+	}
+
+	log.Println("Domain:", domain)
+	log.Println("Authoritative nameserver:", ns, "for zone", zone)
+	log.Println("SOA TTL:", result.SOATTL, "Negative TTL:", result.NegativeTTL)
+	if record.answered {
+		log.Println("A record TTL:", result.RecordTTL)
+	} else {
+		log.Println("No A records")
+	}
+	if result.LowTTLWarning {
+		log.Printf("Warning: TTL below %d seconds\n", LowTTLThreshold)
+	}
+	log.Println("Query time:", result.QueryTime)
+	fmt.Println("------------------------------------------------------------")
+
+	return result
+}
+
+// findAuthoritativeNS returns the zone domain belongs to and one of its nameservers
+func findAuthoritativeNS(resolver *net.Resolver, domain string, timeout time.Duration) (string, string, error) {
+	name := strings.TrimSuffix(domain, ".")
+	for name != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		records, err := resolver.LookupNS(ctx, name)
+		cancel()
+		if err == nil && len(records) > 0 {
+			return name, strings.TrimSuffix(records[0].Host, "."), nil
+		}
+
+		// Names below the zone apex have no NS records of their own
+		_, parent, _ := strings.Cut(name, ".")
+		name = parent
+	}
+
+	return "", "", utils.NewNetworkError("DNS", utils.ErrCodeDNS, "no nameservers found for "+domain, nil)
+}
+
+// ttlResponse holds the TTLs of an authoritative response
+type ttlResponse struct {
+	// answered is set when the answer section had records; recordTTL is the lowest of their TTLs
+	answered  bool
+	recordTTL uint32
+
+	hasSOA    bool
+	soaTTL    uint32
+	soaMinTTL uint32
+}
+
+// queryAuthoritative sends a non-recursive query to server and reads the TTLs of its response.
+// Responses without the authoritative answer flag are rejected.
+func queryAuthoritative(dialer *net.Dialer, server, domain string, qtype dnsmessage.Type, timeout time.Duration) (*ttlResponse, error) {
+	query, err := buildDNSMessage(dnsmessage.Header{ID: uint16(rand.Intn(1 << 16))}, domain, qtype)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := exchangeDNS(dialer, server, query, timeout)
+	if err != nil {
+		return nil, utils.NewNetworkError("DNS", utils.ErrCodeDNS, "query to "+server+" failed", err)
+	}
+
+	var parser dnsmessage.Parser
+	header, err := parser.Start(response)
+	if err != nil {
+		return nil, utils.NewParseError("DNS", utils.ErrCodeParse, "failed to parse DNS response header", err)
+	}
+	if header.RCode != dnsmessage.RCodeSuccess && header.RCode != dnsmessage.RCodeNameError {
+		return nil, utils.NewNetworkError("DNS", utils.ErrCodeDNS, "DNS server returned "+header.RCode.String(), nil)
+	}
+	if !header.Authoritative {
+		return nil, utils.NewNetworkError("DNS", utils.ErrCodeDNS, server+" is not authoritative for "+domain, nil)
+	}
+
+	if err := parser.SkipAllQuestions(); err != nil {
+		return nil, utils.NewParseError("DNS", utils.ErrCodeParse, "failed to skip DNS questions", err)
+	}
+
+	ttls := &ttlResponse{}
+	for {
+		h, err := parser.AnswerHeader()
+		if err == dnsmessage.ErrSectionDone {
+			break
+		}
+		if err != nil {
+			return nil, utils.NewParseError("DNS", utils.ErrCodeParse, "failed to parse DNS answer", err)
+		}
+
+		if !ttls.answered || h.TTL < ttls.recordTTL {
+			ttls.recordTTL = h.TTL
+		}
+		ttls.answered = true
+
+		if h.Type == dnsmessage.TypeSOA {
+			if err := readSOA(&parser, h, ttls); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := parser.SkipAnswer(); err != nil {
+			return nil, utils.NewParseError("DNS", utils.ErrCodeParse, "failed to skip DNS answer", err)
+		}
+	}
+
+	// Negative answers carry the zone's SOA in the authority section
+	for !ttls.hasSOA {
+		h, err := parser.AuthorityHeader()
+		if err == dnsmessage.ErrSectionDone {
+			break
+		}
+		if err != nil {
+			return nil, utils.NewParseError("DNS", utils.ErrCodeParse, "failed to parse DNS authority", err)
+		}
+
+		if h.Type == dnsmessage.TypeSOA {
+			if err := readSOA(&parser, h, ttls); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := parser.SkipAuthority(); err != nil {
+			return nil, utils.NewParseError("DNS", utils.ErrCodeParse, "failed to skip DNS authority", err)
+		}
+	}
+
+	return ttls, nil
+}
+
+// readSOA reads the SOA record whose header is h into ttls
+func readSOA(parser *dnsmessage.Parser, h dnsmessage.ResourceHeader, ttls *ttlResponse) error {
+	soa, err := parser.SOAResource()
+	if err != nil {
+		return utils.NewParseError("DNS", utils.ErrCodeParse, "failed to parse SOA record", err)
+	}
+
+	ttls.hasSOA = true
+	ttls.soaTTL = h.TTL
+	ttls.soaMinTTL = soa.MinTTL
+	return nil
+}
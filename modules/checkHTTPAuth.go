@@ -0,0 +1,107 @@
+package modules
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+)
+
+// CheckHTTPAuth tests an endpoint protected by HTTP authentication.
+// It requests url once without credentials and once with them. Basic authentication is used when
+// username is set, otherwise cfg.HTTPBearerToken is sent as a bearer token.
+//
+// Parameters:
+//   - url: The URL to check (HTTP or HTTPS)
+//   - username: The Basic authentication user name (empty to use the bearer token)
+//   - password: The Basic authentication password
+//   - cfg: Configuration containing the bearer token and timeout settings
+//
+// Returns:
+//   - *HTTPAuthTest: Pointer to HTTPAuthTest struct containing both response statuses and whether authentication succeeded
+//
+// Example:
+//
+//	cfg := config.New()
+//	result := CheckHTTPAuth("https://example.com/admin", "admin", "secret", cfg)
+//	if result.AuthRequired && !result.AuthSuccessful {
+//	    log.Println("Credentials rejected:", result.AuthStatus)
+//	}
+func CheckHTTPAuth(url string, username string, password string, cfg *config.Config) *utils.HTTPAuthTest {
+	result := &utils.HTTPAuthTest{
+		URL: url,
+	}
+
+	if username == "" && cfg.HTTPBearerToken == "" {
+		result.Error = utils.NewValidationError("HTTPAuth", utils.ErrCodeValidation, "no credentials or bearer token configured").Error()
+		log.Println("No credentials for:", url)
+		return result
+	}
+
+	transport, err := newTransport(cfg)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error creating transport:", url, err)
+		return result
+	}
+
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   cfg.HTTPTimeout,
+	}
+
+	resp, err := sendAuth(client, url, cfg, nil)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error sending request:", url, err)
+		return result
+	}
+	result.UnauthStatus = resp.Status
+	result.AuthRequired = resp.StatusCode == http.StatusUnauthorized
+
+	resp, err = sendAuth(client, url, cfg, func(req *http.Request) {
+		if username != "" {
+			req.SetBasicAuth(username, password)
+		} else {
+			req.Header.Set("Authorization", "Bearer "+cfg.HTTPBearerToken)
+		}
+	})
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error sending authenticated request:", url, err)
+		return result
+	}
+	result.AuthStatus = resp.Status
+	result.AuthSuccessful = resp.StatusCode < http.StatusBadRequest
+
+	log.Println("URL:", url)
+	log.Println("Without credentials:", result.UnauthStatus, "auth required:", result.AuthRequired)
+	log.Println("With credentials:", result.AuthStatus, "successful:", result.AuthSuccessful)
+	fmt.Println("------------------------------------------------------------")
+
+	return result
+}
+
+// sendAuth sends a GET request to url, letting authorize add credentials, and discards the response body
+func sendAuth(client *http.Client, url string, cfg *config.Config, authorize func(*http.Request)) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	setUserAgent(req, cfg)
+	if authorize != nil {
+		authorize(req)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	return resp, nil
+}
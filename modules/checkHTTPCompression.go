@@ -0,0 +1,131 @@
+package modules
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+)
+
+// CheckHTTPCompression determines which of the gzip, Brotli and Zstandard content encodings a server uses.
+// It sends one request per encoding, each accepting only that encoding, and checks the Content-Encoding
+// of the response. A further request accepting only identity gives the uncompressed size the
+// compression ratios are computed from, so no Brotli decoder is needed.
+//
+// Parameters:
+//   - url: The URL to check (HTTP or HTTPS)
+//   - cfg: Configuration containing timeout settings
+//
+// Returns:
+//   - *CompressionSupportTest: Pointer to CompressionSupportTest struct containing the supported encodings and their ratios
+//
+// Example:
+//
+//	cfg := config.New()
+//	result := CheckHTTPCompression("https://example.com", cfg)
+//	if result.SupportsBrotli {
+//	    log.Println("Brotli ratio:", result.BrotliRatio)
+//	}
+func CheckHTTPCompression(url string, cfg *config.Config) *utils.CompressionSupportTest {
+	result := &utils.CompressionSupportTest{
+		URL: url,
+	}
+
+	transport, err := newHTTPRoundTripper(cfg)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error creating transport:", url, err)
+		return result
+	}
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   cfg.HTTPTimeout,
+	}
+
+	// Setting Accept-Encoding explicitly stops the transport from decoding gzip bodies,
+	// so every size below is the size on the wire
+	identitySize, _, err := fetchEncoded(client, url, "identity", cfg)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error sending request:", url, err)
+		return result
+	}
+
+	ratio := func(size int64) float64 {
+		if size == 0 || identitySize == 0 {
+			return 0
+		}
+		return float64(identitySize) / float64(size)
+	}
+
+	for _, encoding := range []string{"gzip", "br", "zstd"} {
+		size, used, err := fetchEncoded(client, url, encoding, cfg)
+		if err != nil {
+			log.Println("Error sending", encoding, "request:", url, err)
+			continue
+		}
+		if used != encoding {
+			log.Println("Encoding", encoding, "not used, got:", used)
+			continue
+		}
+
+		switch encoding {
+		case "gzip":
+			result.SupportsGzip = true
+			result.GzipRatio = ratio(size)
+		case "br":
+			result.SupportsBrotli = true
+			result.BrotliRatio = ratio(size)
+		case "zstd":
+			result.SupportsZstd = true
+		}
+		log.Println("Encoding", encoding, "size:", size, "uncompressed:", identitySize)
+	}
+
+	log.Println("URL:", url)
+	log.Println("Supports gzip:", result.SupportsGzip, "Brotli:", result.SupportsBrotli, "Zstandard:", result.SupportsZstd)
+	if result.SupportsGzip {
+		log.Printf("Gzip ratio: %.2f\n", result.GzipRatio)
+	}
+	if result.SupportsBrotli {
+		log.Printf("Brotli ratio: %.2f\n", result.BrotliRatio)
+	}
+	fmt.Println("------------------------------------------------------------")
+
+	return result
+}
+
+// fetchEncoded requests url accepting only encoding and returns the body size on the wire
+// and the Content-Encoding the server used ("identity" when none)
+func fetchEncoded(client *http.Client, url, encoding string, cfg *config.Config) (int64, string, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return 0, "", err
+	}
+	setUserAgent(req, cfg)
+	req.Header.Set("Accept-Encoding", encoding)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	size, err := io.Copy(io.Discard, resp.Body)
+	if err != nil {
+		return 0, "", err
+	}
+	if resp.StatusCode >= 400 {
+		return 0, "", utils.NewNetworkError("HTTP", utils.ErrCodeHTTP, "server returned "+resp.Status, nil)
+	}
+
+	used := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	if used == "" {
+		used = "identity"
+	}
+	return size, used, nil
+}
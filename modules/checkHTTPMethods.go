@@ -0,0 +1,130 @@
+package modules
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+)
+
+// probedHTTPMethods are the methods tried one by one when a server does not answer OPTIONS
+var probedHTTPMethods = []string{
+	http.MethodHead,
+	http.MethodGet,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodDelete,
+	http.MethodPatch,
+	http.MethodTrace,
+	http.MethodConnect,
+}
+
+// CheckHTTPMethods determines which HTTP methods a server allows on url.
+// It first sends an OPTIONS request and uses the Allow response header. When the server does not
+// answer OPTIONS with an Allow header, each method in probedHTTPMethods is sent in turn and counted
+// as allowed unless the server answers 405 Method Not Allowed or 501 Not Implemented.
+// Note that probing sends real POST, PUT, DELETE and PATCH requests without a body.
+//
+// Parameters:
+//   - url: The URL to check (HTTP or HTTPS)
+//   - cfg: Configuration containing timeout settings
+//
+// Returns:
+//   - *HTTPMethodsTest: Pointer to HTTPMethodsTest struct containing the allowed methods and whether TRACE is enabled
+//
+// Example:
+//
+//	cfg := config.New()
+//	result := CheckHTTPMethods("https://example.com", cfg)
+//	if result.TraceEnabled {
+//	    log.Println("TRACE is enabled, cross-site tracing may be possible")
+//	}
+func CheckHTTPMethods(url string, cfg *config.Config) *utils.HTTPMethodsTest {
+	result := &utils.HTTPMethodsTest{
+		URL: url,
+	}
+
+	transport, err := newTransport(cfg)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error creating transport:", url, err)
+		return result
+	}
+
+	// A redirect still means the method was accepted, so redirects are not followed
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   cfg.HTTPTimeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	resp, err := sendMethod(client, http.MethodOptions, url, cfg)
+	if err != nil {
+		log.Println("Error sending OPTIONS request:", url, err)
+	} else if allow := resp.Header.Get("Allow"); resp.StatusCode < 300 && allow != "" {
+		result.ViaOptions = true
+		for _, method := range strings.Split(allow, ",") {
+			if method = strings.ToUpper(strings.TrimSpace(method)); method != "" {
+				result.AllowedMethods = append(result.AllowedMethods, method)
+			}
+		}
+	}
+
+	if !result.ViaOptions {
+		var lastErr error
+		for _, method := range probedHTTPMethods {
+			resp, err := sendMethod(client, method, url, cfg)
+			if err != nil {
+				lastErr = err
+				log.Println("Error sending", method, "request:", url, err)
+				continue
+			}
+			if resp.StatusCode != http.StatusMethodNotAllowed && resp.StatusCode != http.StatusNotImplemented {
+				result.AllowedMethods = append(result.AllowedMethods, method)
+			}
+		}
+
+		if len(result.AllowedMethods) == 0 && lastErr != nil {
+			result.Error = lastErr.Error()
+		}
+	}
+
+	for _, method := range result.AllowedMethods {
+		if method == http.MethodTrace {
+			result.TraceEnabled = true
+		}
+	}
+
+	log.Println("URL:", url)
+	log.Println("Allowed methods:", strings.Join(result.AllowedMethods, ", "), "via OPTIONS:", result.ViaOptions)
+	if result.TraceEnabled {
+		log.Println("TRACE enabled:", url)
+	}
+	fmt.Println("------------------------------------------------------------")
+
+	return result
+}
+
+// sendMethod sends a bodiless request with method to url and discards the response body
+func sendMethod(client *http.Client, method, url string, cfg *config.Config) (*http.Response, error) {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	setUserAgent(req, cfg)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	return resp, nil
+}
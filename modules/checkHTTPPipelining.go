@@ -0,0 +1,145 @@
+package modules
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	neturl "net/url"
+	"time"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+)
+
+// CheckHTTPPipelining checks whether a server answers HTTP/1.1 pipelined requests.
+// Two GET requests are written back-to-back on one connection before any response is read,
+// and pipelining is considered supported when both responses arrive in order.
+//
+// Parameters:
+//   - url: The HTTP or HTTPS URL to request twice
+//   - cfg: Configuration containing timeout and TLS settings
+//
+// Returns:
+//   - *PipeliningTest: Pointer to PipeliningTest struct containing both response statuses and any errors
+//
+// Example:
+//
+//	cfg := config.New()
+//	result := CheckHTTPPipelining("https://example.com/", cfg)
+//	if result.Supported {
+//	    log.Println("Server supports HTTP/1.1 pipelining")
+//	}
+func CheckHTTPPipelining(url string, cfg *config.Config) *utils.PipeliningTest {
+	result := &utils.PipeliningTest{
+		URL: url,
+	}
+
+	u, err := neturl.Parse(url)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error parsing URL:", url, err)
+		return result
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+
+	dialer, err := newDialer(cfg)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error creating dialer:", url, err)
+		return result
+	}
+
+	start := time.Now()
+
+	var conn net.Conn
+	conn, err = dialer.Dial("tcp", net.JoinHostPort(u.Hostname(), port))
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error connecting:", url, err)
+		return result
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(start.Add(cfg.HTTPTimeout)); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	if u.Scheme == "https" {
+		// Pipelining is an HTTP/1.1 feature, so do not offer h2 via ALPN
+		tlsConn := tls.Client(conn, &tls.Config{
+			ServerName: u.Hostname(),
+			NextProtos: []string{"http/1.1"},
+			MinVersion: cfg.TLSMinVersion,
+			MaxVersion: cfg.TLSMaxVersion,
+		})
+		if err := tlsConn.Handshake(); err != nil {
+			result.Error = err.Error()
+			log.Println("TLS handshake failed:", url, err)
+			return result
+		}
+		conn = tlsConn
+	}
+
+	path := u.RequestURI()
+	userAgent := ""
+	if cfg.UserAgent != "" {
+		userAgent = "User-Agent: " + cfg.UserAgent + "\r\n"
+	}
+	request := fmt.Sprintf("GET %s HTTP/1.1\r\nHost: %s\r\n%sConnection: keep-alive\r\n\r\n", path, u.Host, userAgent)
+
+	// Write both requests before reading anything
+	if _, err := io.WriteString(conn, request+request); err != nil {
+		result.Error = err.Error()
+		log.Println("Error writing pipelined requests:", url, err)
+		return result
+	}
+
+	reader := bufio.NewReader(conn)
+	statuses := make([]string, 0, 2)
+	for i := 0; i < 2; i++ {
+		resp, err := http.ReadResponse(reader, nil)
+		if err != nil {
+			result.Error = fmt.Sprintf("reading response %d: %v", i+1, err)
+			log.Println("Error reading pipelined response:", url, err)
+			break
+		}
+
+		// Drain the body so the next response can be read
+		_, err = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		statuses = append(statuses, resp.Status)
+		if err != nil {
+			result.Error = fmt.Sprintf("reading response %d body: %v", i+1, err)
+			break
+		}
+	}
+
+	result.TotalTime = time.Since(start)
+	if len(statuses) > 0 {
+		result.Response1Status = statuses[0]
+	}
+	if len(statuses) > 1 {
+		result.Response2Status = statuses[1]
+		result.Supported = true
+	}
+
+	log.Println("URL:", url)
+	log.Println("Response 1:", result.Response1Status)
+	log.Println("Response 2:", result.Response2Status)
+	log.Println("Pipelining supported:", result.Supported)
+	fmt.Println("------------------------------------------------------------")
+
+	return result
+}
@@ -0,0 +1,94 @@
+package modules
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+)
+
+// CheckHTTPSRedirect checks that a plain HTTP URL redirects to HTTPS.
+// It follows up to cfg.HTTPSRedirectFollowLimit redirects and reports whether the final URL uses
+// the https scheme and whether the final response carries a Strict-Transport-Security header.
+//
+// Parameters:
+//   - url: The http:// URL to check (e.g., "http://example.com")
+//   - cfg: Configuration containing the redirect limit and timeout settings
+//
+// Returns:
+//   - *HTTPSRedirectTest: Pointer to HTTPSRedirectTest struct containing the redirect chain, the final URL and any errors
+//
+// Example:
+//
+//	cfg := config.New()
+//	result := CheckHTTPSRedirect("http://example.com", cfg)
+//	if !result.RedirectsHTTPS {
+//	    log.Println("HTTP is not redirected to HTTPS")
+//	}
+func CheckHTTPSRedirect(url string, cfg *config.Config) *utils.HTTPSRedirectTest {
+	result := &utils.HTTPSRedirectTest{
+		OriginalURL: url,
+	}
+
+	if !strings.HasPrefix(strings.ToLower(url), "http://") {
+		result.Error = utils.NewValidationError("HTTPSRedirect", utils.ErrCodeValidation, "URL must use the http scheme: "+url).Error()
+		log.Println("Not an http:// URL:", url)
+		return result
+	}
+
+	transport, err := newTransport(cfg)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error creating transport:", url, err)
+		return result
+	}
+
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   cfg.HTTPTimeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			// via holds the requests already made, so this is redirect number len(via)
+			if len(via) > cfg.HTTPSRedirectFollowLimit {
+				return fmt.Errorf("stopped after %d redirects", cfg.HTTPSRedirectFollowLimit)
+			}
+			result.RedirectChain = append(result.RedirectChain, req.URL.String())
+			result.RedirectCount = len(via)
+			result.FinalURL = req.URL.String()
+			return nil
+		},
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error creating request:", url, err)
+		return result
+	}
+	setUserAgent(req, cfg)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		result.RedirectsHTTPS = strings.HasPrefix(result.FinalURL, "https://")
+		result.Error = err.Error()
+		log.Println("Error requesting:", url, err)
+		return result
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	result.FinalURL = resp.Request.URL.String()
+	result.RedirectsHTTPS = resp.Request.URL.Scheme == "https"
+	result.HSTSPresent = result.RedirectsHTTPS && resp.Header.Get("Strict-Transport-Security") != ""
+
+	log.Println("URL:", url)
+	log.Println("Redirects:", result.RedirectCount, result.RedirectChain)
+	log.Println("Final URL:", result.FinalURL)
+	log.Println("Redirects to HTTPS:", result.RedirectsHTTPS, "HSTS:", result.HSTSPresent)
+	fmt.Println("------------------------------------------------------------")
+
+	return result
+}
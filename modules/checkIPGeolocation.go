@@ -0,0 +1,101 @@
+package modules
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+)
+
+// CheckIPGeolocation looks up the geographic location of an IP address.
+// It queries cfg.GeolocationAPI, with "{ip}" replaced by the address, and expects an
+// ipinfo.io compatible JSON response.
+//
+// Parameters:
+//   - ip: The IP address to locate
+//   - cfg: Configuration containing the geolocation API and timeout settings
+//
+// Returns:
+//   - *GeoTest: Pointer to GeoTest struct containing the location details and any errors
+//
+// Example:
+//
+//	cfg := config.New()
+//	result := CheckIPGeolocation("8.8.8.8", cfg)
+//	if result.Error == "" {
+//	    log.Println("Located in:", result.City, result.Country)
+//	}
+func CheckIPGeolocation(ip string, cfg *config.Config) *utils.GeoTest {
+	result := &utils.GeoTest{
+		IP: ip,
+	}
+
+	if net.ParseIP(ip) == nil {
+		result.Error = utils.NewValidationError("Geo", utils.ErrCodeValidation, "invalid IP address: "+ip).Error()
+		log.Println("Invalid IP address:", ip)
+		return result
+	}
+
+	apiURL := strings.ReplaceAll(cfg.GeolocationAPI, "{ip}", url.PathEscape(ip))
+
+	req, err := http.NewRequest(http.MethodGet, apiURL, nil)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error creating request:", apiURL, err)
+		return result
+	}
+	req.Header.Set("Accept", "application/json")
+	setUserAgent(req, cfg)
+
+	transport, err := newTransport(cfg)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error creating transport:", apiURL, err)
+		return result
+	}
+
+	client := http.Client{
+		Transport: transport,
+		Timeout:   cfg.HTTPTimeout,
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error querying geolocation API:", apiURL, err)
+		return result
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		result.Error = utils.NewNetworkError("Geo", utils.ErrCodeHTTP, "unexpected status "+resp.Status, nil).Error()
+		log.Println("Geolocation API returned:", resp.Status)
+		return result
+	}
+
+	var geo utils.GeoTest
+	if err := json.NewDecoder(resp.Body).Decode(&geo); err != nil {
+		result.Error = utils.NewParseError("Geo", utils.ErrCodeParse, "failed to parse geolocation response", err).Error()
+		log.Println("Error parsing geolocation response:", err)
+		return result
+	}
+
+	// Keep the queried address even if the API echoes it differently
+	geo.IP = ip
+	geo.Error = ""
+	*result = geo
+
+	log.Println("IP:", ip)
+	log.Println("Location:", result.City, result.Region, result.Country, result.Loc)
+	log.Println("Organization:", result.Org)
+	log.Println("Timezone:", result.Timezone)
+	fmt.Println("------------------------------------------------------------")
+
+	return result
+}
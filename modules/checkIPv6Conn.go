@@ -0,0 +1,175 @@
+package modules
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+	"github.com/go-ping/ping"
+)
+
+const (
+	// IPv6TestURL is an HTTP site reachable only over IPv6
+	IPv6TestURL = "http://ipv6.google.com"
+
+	// IPv6TestHost is the IPv6-only host name resolved by the DNS part of CheckIPv6Connectivity
+	IPv6TestHost = "ipv6.google.com"
+
+	// IPv6PingTarget is Google Public DNS, which answers ICMPv6 echo requests
+	IPv6PingTarget = "2001:4860:4860::8888"
+)
+
+// CheckIPv6Connectivity checks whether IPv6 actually works rather than merely being configured.
+// It resolves the AAAA records of an IPv6-only host, fetches an IPv6-only web site over a TCP6
+// connection and pings Google Public DNS over ICMPv6. Each reachable check adds one to the score.
+// The external IPv6 address is looked up from cfg.IPv6Checker when HTTP over IPv6 works.
+//
+// Parameters:
+//   - cfg: Configuration containing the IPv6 checker URL, DNS resolver, ping and timeout settings
+//
+// Returns:
+//   - *IPv6ConnTest: Pointer to IPv6ConnTest struct containing the reachability of each check and the score
+//
+// Example:
+//
+//	cfg := config.New()
+//	result := CheckIPv6Connectivity(cfg)
+//	if result.ConnectivityScore < 3 {
+//	    log.Println("IPv6 is partially broken:", result.Error)
+//	}
+func CheckIPv6Connectivity(cfg *config.Config) *utils.IPv6ConnTest {
+	result := &utils.IPv6ConnTest{}
+
+	var failures []string
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.DNSTimeout)
+	ips, err := newResolver(cfg.DNSResolver, cfg.DNSTimeout).LookupIP(ctx, "ip6", IPv6TestHost)
+	cancel()
+	if err != nil || len(ips) == 0 {
+		failures = append(failures, fmt.Sprintf("DNS: no AAAA records for %s: %v", IPv6TestHost, err))
+		log.Println("IPv6 DNS lookup failed:", IPv6TestHost, err)
+	} else {
+		result.DNSReachable = true
+	}
+
+	client, err := newIPv6Client(cfg)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error creating transport:", err)
+		return result
+	}
+
+	if resp, err := ipv6Get(client, IPv6TestURL, cfg); err != nil {
+		failures = append(failures, "HTTP: "+err.Error())
+		log.Println("IPv6 HTTP request failed:", IPv6TestURL, err)
+	} else {
+		resp.Body.Close()
+		result.HTTPReachable = true
+	}
+
+	if received, err := ping6(IPv6PingTarget, cfg); err != nil || received == 0 {
+		if err == nil {
+			err = fmt.Errorf("no replies from %s", IPv6PingTarget)
+		}
+		failures = append(failures, "ICMP: "+err.Error())
+		log.Println("IPv6 ping failed:", IPv6PingTarget, err)
+	} else {
+		result.ICMPReachable = true
+	}
+
+	if result.HTTPReachable {
+		result.ExternalIPv6 = externalIPv6(client, cfg)
+	}
+
+	for _, ok := range []bool{result.DNSReachable, result.HTTPReachable, result.ICMPReachable} {
+		if ok {
+			result.ConnectivityScore++
+		}
+	}
+	if len(failures) > 0 {
+		result.Error = utils.NewNetworkError("IPv6", utils.ErrCodeNetwork, strings.Join(failures, "; "), nil).Error()
+	}
+
+	log.Println("IPv6 DNS reachable:", result.DNSReachable)
+	log.Println("IPv6 HTTP reachable:", result.HTTPReachable)
+	log.Println("IPv6 ICMP reachable:", result.ICMPReachable)
+	log.Println("External IPv6:", result.ExternalIPv6)
+	log.Printf("IPv6 connectivity score: %d/3\n", result.ConnectivityScore)
+	fmt.Println("------------------------------------------------------------")
+
+	return result
+}
+
+// newIPv6Client returns an HTTP client whose connections are made over IPv6 only
+func newIPv6Client(cfg *config.Config) (*http.Client, error) {
+	transport, err := newTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	dial := transport.DialContext
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return dial(ctx, "tcp6", addr)
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   cfg.HTTPTimeout,
+	}, nil
+}
+
+// ipv6Get sends a GET request to url with client
+func ipv6Get(client *http.Client, url string, cfg *config.Config) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	setUserAgent(req, cfg)
+
+	return client.Do(req)
+}
+
+// externalIPv6 asks cfg.IPv6Checker for the external IPv6 address, returning "" on failure
+func externalIPv6(client *http.Client, cfg *config.Config) string {
+	resp, err := ipv6Get(client, cfg.IPv6Checker, cfg)
+	if err != nil {
+		log.Println("Error getting external IPv6:", cfg.IPv6Checker, err)
+		return ""
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 256))
+	if err != nil {
+		log.Println("Error reading external IPv6:", cfg.IPv6Checker, err)
+		return ""
+	}
+
+	ip := net.ParseIP(strings.TrimSpace(string(body)))
+	if ip == nil || ip.To4() != nil {
+		log.Println("Invalid external IPv6 address:", strings.TrimSpace(string(body)))
+		return ""
+	}
+	return ip.String()
+}
+
+// ping6 sends cfg.PingCount ICMPv6 echo requests to addr and returns how many were answered
+func ping6(addr string, cfg *config.Config) (int, error) {
+	pinger, err := ping.NewPinger(addr)
+	if err != nil {
+		return 0, err
+	}
+	pinger.SetNetwork("ip6")
+	pinger.Count = cfg.PingCount
+	pinger.Timeout = cfg.PingTimeout
+
+	if err := pinger.Run(); err != nil {
+		return 0, err
+	}
+	return pinger.Statistics().PacketsRecv, nil
+}
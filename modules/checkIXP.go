@@ -0,0 +1,120 @@
+package modules
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+)
+
+const (
+	// BGPPort is the TCP port BGP speakers listen on
+	BGPPort = 179
+
+	// bgpHeaderLength is the size of the BGP message header: a 16 byte marker, length and type
+	bgpHeaderLength = 19
+
+	// bgpOpenLength is the size of an OPEN message without optional parameters
+	bgpOpenLength = 29
+
+	// bgpMessageOpen is the BGP message type of OPEN
+	bgpMessageOpen = 1
+
+	// bgpBannerWait is how long to wait for a route server that sends its OPEN message unprompted
+	bgpBannerWait = 2 * time.Second
+)
+
+// CheckInternetExchangeReachability checks that the BGP port of an Internet Exchange Point route server
+// is reachable. It only opens a TCP connection to port 179 and never sends a BGP message, so no session
+// is established. Some BGP speakers send their OPEN message as soon as the connection is up; when one
+// arrives within a short wait its header is reported as the banner.
+//
+// Parameters:
+//   - ixp: The route server host name (e.g., "route-server.ams-ix.net")
+//   - cfg: Configuration containing connect timeout and local address settings
+//
+// Returns:
+//   - *IXPTest: Pointer to IXPTest struct containing reachability, dial time and any OPEN message received
+//
+// Example:
+//
+//	cfg := config.New()
+//	result := CheckInternetExchangeReachability("route-server.ams-ix.net", cfg)
+//	if !result.Reachable {
+//	    log.Println("Route server unreachable:", result.Error)
+//	}
+func CheckInternetExchangeReachability(ixp string, cfg *config.Config) *utils.IXPTest {
+	result := &utils.IXPTest{
+		IXP: ixp,
+	}
+
+	dialer, err := newDialer(cfg)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error creating dialer:", ixp, err)
+		return result
+	}
+	dialer.Timeout = cfg.HTTPConnectTimeout
+
+	addr := net.JoinHostPort(ixp, strconv.Itoa(BGPPort))
+	start := time.Now()
+	conn, err := dialer.Dial("tcp", addr)
+	result.DialTime = time.Since(start)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error connecting to route server:", addr, err)
+		fmt.Println("------------------------------------------------------------")
+		return result
+	}
+	defer conn.Close()
+
+	result.Reachable = true
+	result.RouteServer = conn.RemoteAddr().String()
+
+	// Silence is expected since most route servers wait for the peer to open the session
+	if err := conn.SetReadDeadline(time.Now().Add(bgpBannerWait)); err == nil {
+		msg := make([]byte, bgpOpenLength)
+		if _, err := io.ReadFull(conn, msg); err == nil {
+			result.Banner = parseBGPOpen(msg)
+		}
+	}
+
+	log.Println("IXP route server:", ixp, "at", result.RouteServer)
+	log.Println("Reachable:", result.Reachable, "dial time:", result.DialTime)
+	if result.Banner != "" {
+		log.Println("BGP OPEN:", result.Banner)
+	}
+	fmt.Println("------------------------------------------------------------")
+
+	return result
+}
+
+// parseBGPOpen describes a BGP OPEN message (RFC 4271 section 4.2).
+// It returns an empty string when msg is not an OPEN message.
+func parseBGPOpen(msg []byte) string {
+	if len(msg) < bgpOpenLength {
+		return ""
+	}
+	for _, b := range msg[:16] {
+		if b != 0xff {
+			return ""
+		}
+	}
+	if msg[18] != bgpMessageOpen {
+		return ""
+	}
+
+	open := msg[bgpHeaderLength:]
+	version := open[0]
+	asn := binary.BigEndian.Uint16(open[1:3])
+	holdTime := binary.BigEndian.Uint16(open[3:5])
+	id := net.IP(open[5:9])
+
+	return fmt.Sprintf("BGP-%d OPEN AS%d hold %ds id %s", version, asn, holdTime, id)
+}
@@ -0,0 +1,148 @@
+package modules
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"time"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+)
+
+// RegionalLatencyTargets are anycast services answered by a nearby point of presence,
+// so their latency reflects the path to the closest internet exchange
+var RegionalLatencyTargets = []string{
+	"1.1.1.1:443", // Cloudflare
+	"8.8.8.8:443", // Google
+	"9.9.9.9:443", // Quad9
+}
+
+// GlobalLatencyTargets are unicast hosts on different continents
+var GlobalLatencyTargets = []string{
+	"www.ripe.net:443",    // Europe
+	"www.arin.net:443",    // North America
+	"www.lacnic.net:443",  // South America
+	"www.afrinic.net:443", // Africa
+	"www.apnic.net:443",   // Asia Pacific
+}
+
+// latencyProfileSamples is the number of connections made to each target; the fastest one counts
+const latencyProfileSamples = 3
+
+// CheckNetworkLatencyProfile measures a baseline of network latency to put other results into context.
+// It times TCP connections, which unlike ICMP need no privileges, to RegionalLatencyTargets and
+// GlobalLatencyTargets. LocalLoop is the fastest connection seen to any target, RegionalAvg and
+// GlobalAvg average the targets of each group, and EstimatedTier guesses the access technology
+// from the regional latency. All latencies are in milliseconds; unreachable targets are skipped.
+//
+// Parameters:
+//   - cfg: Configuration containing connect timeout and local address settings
+//
+// Returns:
+//   - *LatencyProfile: Pointer to LatencyProfile struct containing the latency baseline and estimated tier
+//
+// Example:
+//
+//	cfg := config.New()
+//	profile := CheckNetworkLatencyProfile(cfg)
+//	log.Printf("%.1f ms to the nearest exchange, likely %s\n", profile.RegionalAvg, profile.EstimatedTier)
+func CheckNetworkLatencyProfile(cfg *config.Config) *utils.LatencyProfile {
+	result := &utils.LatencyProfile{}
+
+	dialer, err := newDialer(cfg)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error creating dialer:", err)
+		return result
+	}
+	dialer.Timeout = cfg.HTTPConnectTimeout
+
+	regional := measureTargets(dialer, RegionalLatencyTargets)
+	global := measureTargets(dialer, GlobalLatencyTargets)
+
+	if len(regional) == 0 && len(global) == 0 {
+		result.Error = utils.NewNetworkError("LatencyProfile", utils.ErrCodeNetwork, "no latency target was reachable", nil).Error()
+		result.EstimatedTier = "unknown"
+		log.Println("No latency target was reachable")
+		fmt.Println("------------------------------------------------------------")
+		return result
+	}
+
+	result.LocalLoop = math.Inf(1)
+	for _, rtt := range append(append([]float64{}, regional...), global...) {
+		result.LocalLoop = math.Min(result.LocalLoop, rtt)
+	}
+	result.RegionalAvg = average(regional)
+	result.GlobalAvg = average(global)
+
+	// Without regional measurements the fastest connection is the best estimate of the access latency
+	access := result.RegionalAvg
+	if len(regional) == 0 {
+		access = result.LocalLoop
+	}
+	result.EstimatedTier = estimateTier(access)
+
+	log.Printf("Local loop: %.1f ms\n", result.LocalLoop)
+	log.Printf("Regional average: %.1f ms (%d of %d targets)\n", result.RegionalAvg, len(regional), len(RegionalLatencyTargets))
+	log.Printf("Global average: %.1f ms (%d of %d targets)\n", result.GlobalAvg, len(global), len(GlobalLatencyTargets))
+	log.Println("Estimated tier:", result.EstimatedTier)
+	fmt.Println("------------------------------------------------------------")
+
+	return result
+}
+
+// measureTargets returns the fastest TCP connect time in milliseconds of each reachable target
+func measureTargets(dialer *net.Dialer, targets []string) []float64 {
+	var rtts []float64
+	for _, target := range targets {
+		best := time.Duration(0)
+		for i := 0; i < latencyProfileSamples; i++ {
+			start := time.Now()
+			conn, err := dialer.Dial("tcp", target)
+			if err != nil {
+				log.Println("Error connecting to latency target:", target, err)
+				break
+			}
+			rtt := time.Since(start)
+			conn.Close()
+
+			if best == 0 || rtt < best {
+				best = rtt
+			}
+		}
+		if best > 0 {
+			rtts = append(rtts, durationMS(best))
+		}
+	}
+	return rtts
+}
+
+// average returns the mean of values, or 0 when there are none
+func average(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// estimateTier guesses the access technology from the latency in milliseconds to a nearby exchange
+func estimateTier(ms float64) string {
+	switch {
+	case ms < 10:
+		return "fiber"
+	case ms < 40:
+		return "cable/dsl"
+	case ms < 150:
+		return "mobile"
+	case ms < 400:
+		return "congested or long-haul"
+	default:
+		return "satellite"
+	}
+}
@@ -0,0 +1,101 @@
+package modules
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+)
+
+// CheckLatencyVariance characterizes the latency distribution of url.
+// It sends samples sequential HEAD requests over a reused connection and computes the mean,
+// standard deviation, coefficient of variation and 95th percentile of the response times.
+// Failed requests are logged and left out of the statistics.
+//
+// Parameters:
+//   - url: The URL to sample (HTTP or HTTPS)
+//   - samples: The number of requests to send
+//   - cfg: Configuration containing timeout settings and whether to keep the raw samples
+//
+// Returns:
+//   - *LatencyVarianceTest: Pointer to LatencyVarianceTest struct containing the latency statistics and any errors
+//
+// Example:
+//
+//	cfg := config.New()
+//	result := CheckLatencyVariance("https://example.com", 20, cfg)
+//	if result.CV > 0.5 {
+//	    log.Println("Latency is unstable, p95:", result.P95)
+//	}
+func CheckLatencyVariance(url string, samples int, cfg *config.Config) *utils.LatencyVarianceTest {
+	result := &utils.LatencyVarianceTest{
+		URL: url,
+	}
+
+	if samples < 1 {
+		result.Error = utils.NewValidationError("Latency", utils.ErrCodeValidation, "samples must be at least 1").Error()
+		log.Println("Invalid sample count:", samples)
+		return result
+	}
+
+	transport, err := newTransport(cfg)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error creating transport:", url, err)
+		return result
+	}
+
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   cfg.HTTPTimeout,
+	}
+
+	var latencies []time.Duration
+	var lastErr error
+	for i := 0; i < samples; i++ {
+		req, err := http.NewRequest(http.MethodHead, url, nil)
+		if err != nil {
+			result.Error = err.Error()
+			log.Println("Error creating request:", url, err)
+			return result
+		}
+		setUserAgent(req, cfg)
+
+		start := time.Now()
+		resp, err := client.Do(req)
+		if err != nil {
+			lastErr = err
+			log.Println("Error sending request:", url, err)
+			continue
+		}
+		latency := time.Since(start)
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		latencies = append(latencies, latency)
+	}
+
+	if len(latencies) == 0 {
+		result.Error = lastErr.Error()
+		fmt.Println("------------------------------------------------------------")
+		return result
+	}
+
+	result.Samples = len(latencies)
+	result.MeanLatency, result.StdDev, result.P95, result.CV = utils.AggregateLatencies(latencies)
+	if cfg.RecordLatencySamples {
+		result.LatencySamples = latencies
+	}
+
+	log.Println("URL:", url)
+	log.Println("Samples:", result.Samples, "of", samples)
+	log.Println("Mean latency:", result.MeanLatency, "stddev:", result.StdDev, "p95:", result.P95)
+	log.Printf("Coefficient of variation: %.3f\n", result.CV)
+	fmt.Println("------------------------------------------------------------")
+
+	return result
+}
@@ -0,0 +1,118 @@
+package modules
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+)
+
+// SMTPPort is the port mail servers accept mail from other servers on
+const SMTPPort = 25
+
+// CheckMXReachability checks that the mail servers of a domain accept connections.
+// It looks up the MX records of domain, then connects to port 25 of each mail server in order of
+// preference and reads its SMTP greeting. No mail is sent; the connection is closed with QUIT.
+//
+// Parameters:
+//   - domain: The mail domain to check (e.g., "example.com")
+//   - cfg: Configuration containing DNS resolver, timeout and local address settings
+//
+// Returns:
+//   - *MXReachabilityTest: Pointer to MXReachabilityTest struct containing the result for every mail server
+//
+// Example:
+//
+//	cfg := config.New()
+//	result := CheckMXReachability("example.com", cfg)
+//	if !result.AllReachable {
+//	    log.Println("Some mail servers of example.com are unreachable")
+//	}
+func CheckMXReachability(domain string, cfg *config.Config) *utils.MXReachabilityTest {
+	result := &utils.MXReachabilityTest{
+		Domain: domain,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.DNSTimeout)
+	mxs, err := newResolver(cfg.DNSResolver, cfg.DNSTimeout).LookupMX(ctx, domain)
+	cancel()
+	if err != nil {
+		result.Error = utils.NewNetworkError("MX", utils.ErrCodeDNS, "MX lookup failed", err).Error()
+		log.Println("Error looking up MX records:", domain, err)
+		fmt.Println("------------------------------------------------------------")
+		return result
+	}
+
+	// A single "." MX is a null MX (RFC 7505): the domain accepts no mail
+	if len(mxs) == 1 && (mxs[0].Host == "." || mxs[0].Host == "") {
+		result.Error = utils.NewValidationError("MX", utils.ErrCodeValidation, "domain publishes a null MX and accepts no mail").Error()
+		log.Println("Null MX:", domain)
+		fmt.Println("------------------------------------------------------------")
+		return result
+	}
+
+	dialer, err := newDialer(cfg)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error creating dialer:", domain, err)
+		return result
+	}
+	dialer.Timeout = cfg.HTTPConnectTimeout
+
+	// LookupMX returns the records sorted by preference
+	result.AllReachable = true
+	for _, mx := range mxs {
+		mr := utils.MXResult{
+			Hostname: strings.TrimSuffix(mx.Host, "."),
+			Priority: int(mx.Pref),
+		}
+
+		mr.Banner, err = readSMTPBanner(dialer, mr.Hostname, cfg.HTTPTimeout)
+		if err != nil {
+			mr.Error = err.Error()
+			result.AllReachable = false
+			log.Println("Mail server unreachable:", mr.Hostname, err)
+		} else {
+			mr.Reachable = true
+			log.Println("Mail server:", mr.Hostname, "priority", mr.Priority, "banner:", mr.Banner)
+		}
+
+		result.MXRecords = append(result.MXRecords, mr)
+	}
+
+	log.Println("Domain:", domain)
+	log.Println("Mail servers:", len(result.MXRecords), "all reachable:", result.AllReachable)
+	fmt.Println("------------------------------------------------------------")
+
+	return result
+}
+
+// readSMTPBanner connects to port 25 of host and returns its 220 greeting
+func readSMTPBanner(dialer *net.Dialer, host string, timeout time.Duration) (string, error) {
+	conn, err := dialer.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(SMTPPort)))
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return "", err
+	}
+
+	// Multi-line greetings are joined with newlines by ReadResponse
+	_, msg, err := textproto.NewReader(bufio.NewReader(conn)).ReadResponse(220)
+	if err != nil {
+		return "", err
+	}
+
+	fmt.Fprint(conn, "QUIT\r\n")
+	return strings.ReplaceAll(msg, "\n", " "), nil
+}
@@ -0,0 +1,137 @@
+package modules
+
+import (
+	"fmt"
+	"log"
+	"net"
+	neturl "net/url"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+)
+
+// MultiHomedTestURL is downloaded through every interface by CheckMultiHomed
+var MultiHomedTestURL = "https://speed.cloudflare.com/__down?bytes=10000000"
+
+// CheckMultiHomed compares the network paths of a host with several network interfaces.
+// For every interface that is up and not a loopback, it binds connections to the interface's
+// primary IP (its first IPv4 address, else its first global IPv6 address) and measures the
+// download speed of MultiHomedTestURL and the TCP connect latency to its host. Interfaces are
+// tested one after another so their downloads do not compete; interfaces without a usable IP
+// are skipped.
+//
+// Parameters:
+//   - cfg: Configuration containing timeout settings; its source IP and interface are overridden per interface
+//
+// Returns:
+//   - *MultiHomedTest: Pointer to MultiHomedTest struct containing the results per interface
+//
+// Example:
+//
+//	cfg := config.New()
+//	result := CheckMultiHomed(cfg)
+//	for _, iface := range result.Interfaces {
+//	    log.Printf("%s: %.1f Mbps, %.1f ms\n", iface.Name, iface.DownloadMbps, iface.LatencyMs)
+//	}
+func CheckMultiHomed(cfg *config.Config) *utils.MultiHomedTest {
+	result := &utils.MultiHomedTest{}
+
+	u, err := neturl.Parse(MultiHomedTestURL)
+	if err != nil || u.Hostname() == "" {
+		result.Error = utils.NewValidationError("MultiHomed", utils.ErrCodeValidation, "invalid test URL: "+MultiHomedTestURL).Error()
+		log.Println("Invalid multi-homed test URL:", MultiHomedTestURL)
+		return result
+	}
+	port := u.Port()
+	if port == "" {
+		port = "443"
+		if u.Scheme == "http" {
+			port = "80"
+		}
+	}
+	target := net.JoinHostPort(u.Hostname(), port)
+
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error listing network interfaces:", err)
+		return result
+	}
+
+	for _, ifi := range interfaces {
+		if ifi.Flags&net.FlagUp == 0 || ifi.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		ip := primaryIP(ifi)
+		if ip == nil {
+			continue
+		}
+
+		iface := utils.InterfaceResult{Name: ifi.Name, IP: ip.String()}
+
+		// Bind every connection of this interface's tests to its IP
+		ifaceCfg := *cfg
+		ifaceCfg.SourceIP = iface.IP
+		ifaceCfg.LocalInterface = ""
+
+		dialer, err := newDialer(&ifaceCfg)
+		if err != nil {
+			iface.Error = err.Error()
+			log.Println("Error creating dialer:", ifi.Name, err)
+			result.Interfaces = append(result.Interfaces, iface)
+			continue
+		}
+		dialer.Timeout = cfg.HTTPConnectTimeout
+
+		if rtts := measureTargets(dialer, []string{target}); len(rtts) > 0 {
+			iface.LatencyMs = rtts[0]
+		}
+
+		speed := CheckSpeed(MultiHomedTestURL, &ifaceCfg)
+		iface.DownloadMbps = speed.DownloadMbps
+		iface.Error = speed.Error
+
+		result.Interfaces = append(result.Interfaces, iface)
+	}
+
+	if len(result.Interfaces) == 0 {
+		result.Error = utils.NewNetworkError("MultiHomed", utils.ErrCodeNetwork, "no network interface with a usable IP address", nil).Error()
+		log.Println("No network interface with a usable IP address")
+		fmt.Println("------------------------------------------------------------")
+		return result
+	}
+
+	for _, iface := range result.Interfaces {
+		if iface.Error != "" {
+			log.Printf("Interface %s (%s): %s\n", iface.Name, iface.IP, iface.Error)
+			continue
+		}
+		log.Printf("Interface %s (%s): %.2f Mbps, %.1f ms\n", iface.Name, iface.IP, iface.DownloadMbps, iface.LatencyMs)
+	}
+	fmt.Println("------------------------------------------------------------")
+
+	return result
+}
+
+// primaryIP returns the first IPv4 address of ifi, else its first global IPv6 address, or nil
+func primaryIP(ifi net.Interface) net.IP {
+	addrs, err := ifi.Addrs()
+	if err != nil {
+		return nil
+	}
+
+	var ipv6 net.IP
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLoopback() || ipNet.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		if ip4 := ipNet.IP.To4(); ip4 != nil {
+			return ip4
+		}
+		if ipv6 == nil {
+			ipv6 = ipNet.IP
+		}
+	}
+	return ipv6
+}
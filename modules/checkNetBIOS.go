@@ -0,0 +1,200 @@
+package modules
+
+import (
+	"encoding/binary"
+	"fmt"
+	"log"
+	"math/rand"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+)
+
+const (
+	// NetBIOSNameServicePort is the UDP port of the NetBIOS Name Service
+	NetBIOSNameServicePort = "137"
+
+	// nbstatType is the NBSTAT (node status) question type of RFC 1002
+	nbstatType = 0x0021
+
+	// netbiosGroupFlag marks a group name in the name flags of a node status response
+	netbiosGroupFlag = 0x8000
+
+	// netbiosNameEntryLen is the size of a node name entry: 15 byte name, suffix and flags
+	netbiosNameEntryLen = 18
+)
+
+// CheckNetBIOS queries the NetBIOS names registered by a host using an NBNS node status request.
+// This is the query "nbtstat -A" sends; it reveals the host's names, its workgroup or domain
+// and the MAC address of its adapter, and confirms the host is reachable for SMB over NetBIOS.
+//
+// Parameters:
+//   - host: The host name or IP address to query
+//   - cfg: Configuration containing timeout and local interface settings
+//
+// Returns:
+//   - *NetBIOSTest: Pointer to NetBIOSTest struct containing the names, workgroup, MAC address and any errors
+//
+// Example:
+//
+//	cfg := config.New()
+//	result := CheckNetBIOS("192.168.1.10", cfg)
+//	if result.Error == "" {
+//	    log.Println("Names:", result.Names, "workgroup:", result.Workgroup)
+//	}
+func CheckNetBIOS(host string, cfg *config.Config) *utils.NetBIOSTest {
+	result := &utils.NetBIOSTest{
+		Host: host,
+	}
+
+	dialer, err := newUDPDialer(cfg)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error creating dialer:", host, err)
+		return result
+	}
+
+	conn, err := dialer.Dial("udp", net.JoinHostPort(host, NetBIOSNameServicePort))
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error connecting to NetBIOS name service:", host, err)
+		return result
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(cfg.PingTimeout)); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	id := uint16(rand.Intn(1 << 16))
+	start := time.Now()
+	if _, err := conn.Write(buildNBSTATQuery(id)); err != nil {
+		result.Error = err.Error()
+		log.Println("Error sending NetBIOS query:", host, err)
+		return result
+	}
+
+	response := make([]byte, 1024)
+	n, err := conn.Read(response)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("No NetBIOS response from:", host, err)
+		fmt.Println("------------------------------------------------------------")
+		return result
+	}
+	result.RTT = time.Since(start)
+
+	if err := parseNBSTATResponse(response[:n], id, result); err != nil {
+		result.Error = err.Error()
+		log.Println("Error parsing NetBIOS response:", host, err)
+		return result
+	}
+
+	log.Println("Host:", host)
+	log.Println("NetBIOS names:", result.Names)
+	log.Println("Workgroup:", result.Workgroup)
+	log.Println("MAC address:", result.MACAddress)
+	log.Println("RTT:", result.RTT)
+	fmt.Println("------------------------------------------------------------")
+
+	return result
+}
+
+// buildNBSTATQuery encodes a node status request for the wildcard name "*"
+func buildNBSTATQuery(id uint16) []byte {
+	msg := make([]byte, 12, 50)
+	binary.BigEndian.PutUint16(msg[0:], id)
+	binary.BigEndian.PutUint16(msg[4:], 1) // QDCOUNT
+
+	// First level encoding (RFC 1001 section 14.1) of "*" padded with NUL bytes to 16 bytes
+	name := make([]byte, 16)
+	name[0] = '*'
+	msg = append(msg, 32)
+	for _, b := range name {
+		msg = append(msg, 'A'+b>>4, 'A'+b&0x0F)
+	}
+	msg = append(msg, 0)
+
+	msg = binary.BigEndian.AppendUint16(msg, nbstatType)
+	msg = binary.BigEndian.AppendUint16(msg, 1) // class IN
+	return msg
+}
+
+// parseNBSTATResponse fills result with the names, workgroup and MAC address of a node status response
+func parseNBSTATResponse(msg []byte, id uint16, result *utils.NetBIOSTest) error {
+	malformed := func(reason string) error {
+		return utils.NewParseError("NetBIOS", utils.ErrCodeParse, "malformed node status response: "+reason, nil)
+	}
+
+	if len(msg) < 12 {
+		return malformed("short header")
+	}
+	if binary.BigEndian.Uint16(msg[0:]) != id {
+		return malformed("transaction ID mismatch")
+	}
+	if rcode := msg[3] & 0x0F; rcode != 0 {
+		return utils.NewNetworkError("NetBIOS", utils.ErrCodeDNS, fmt.Sprintf("name service returned error code %d", rcode), nil)
+	}
+	if binary.BigEndian.Uint16(msg[6:]) == 0 {
+		return malformed("no answer")
+	}
+
+	// Skip the answer name, which is either a compression pointer or a sequence of labels
+	off := 12
+	for {
+		if off >= len(msg) {
+			return malformed("truncated name")
+		}
+		if msg[off]&0xC0 == 0xC0 {
+			off += 2
+			break
+		}
+		if msg[off] == 0 {
+			off++
+			break
+		}
+		off += 1 + int(msg[off])
+	}
+
+	// Type, class, TTL and RDLENGTH precede the name count
+	off += 10
+	if off >= len(msg) {
+		return malformed("truncated resource record")
+	}
+
+	count := int(msg[off])
+	off++
+	if off+count*netbiosNameEntryLen > len(msg) {
+		return malformed("truncated name table")
+	}
+
+	for i := 0; i < count; i++ {
+		entry := msg[off : off+netbiosNameEntryLen]
+		off += netbiosNameEntryLen
+
+		name := strings.TrimRight(string(entry[:15]), " \x00")
+		suffix := entry[15]
+		flags := binary.BigEndian.Uint16(entry[16:])
+
+		if flags&netbiosGroupFlag != 0 {
+			// The group name with suffix 00 is the workgroup or domain
+			if suffix == 0x00 && result.Workgroup == "" {
+				result.Workgroup = name
+			}
+			continue
+		}
+
+		result.Names = append(result.Names, fmt.Sprintf("%s<%02X>", name, suffix))
+	}
+
+	// The statistics block starts with the 6 byte unit ID, the MAC address of the adapter
+	if off+6 <= len(msg) {
+		result.MACAddress = net.HardwareAddr(msg[off : off+6]).String()
+	}
+
+	return nil
+}
@@ -0,0 +1,168 @@
+package modules
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+)
+
+// ACL verdicts reported by checkACLPort
+const (
+	ACLAllowed  = "Allowed"
+	ACLDenied   = "Denied"
+	ACLTimedOut = "Timeout"
+)
+
+// aclProbe is the payload sent to UDP ports to provoke a response
+var aclProbe = []byte("\r\n")
+
+// CheckNetworkACL checks which ports of a target IP the network lets through for a protocol.
+// TCP ports are allowed when a connection is established and denied when it is refused.
+// UDP ports are probed with a small datagram: any reply means allowed, an ICMP port
+// unreachable means denied. Ports without any answer are reported as timed out, which for
+// UDP may also mean the port is open but the service ignored the probe.
+// Probes run concurrently, bounded by cfg.WorkerCount.
+//
+// Parameters:
+//   - targetIP: The IP address to test
+//   - ports: Destination ports to test
+//   - protocol: "tcp" or "udp"
+//   - cfg: Configuration containing connect timeout, local interface and worker count settings
+//
+// Returns:
+//   - *ACLTest: Pointer to ACLTest struct containing the verdict for every port and any errors
+//
+// Example:
+//
+//	cfg := config.New()
+//	result := CheckNetworkACL("192.0.2.10", []int{22, 443}, "tcp", cfg)
+//	log.Println("Denied ports:", result.Denied)
+func CheckNetworkACL(targetIP string, ports []int, protocol string, cfg *config.Config) *utils.ACLTest {
+	result := &utils.ACLTest{
+		TargetIP: targetIP,
+		Protocol: protocol,
+		Allowed:  make(map[int]bool, len(ports)),
+	}
+
+	if net.ParseIP(targetIP) == nil {
+		result.Error = utils.NewValidationError("ACL", utils.ErrCodeValidation, "invalid target IP: "+targetIP).Error()
+		log.Println("Invalid target IP:", targetIP)
+		return result
+	}
+
+	if protocol != "tcp" && protocol != "udp" {
+		result.Error = utils.NewValidationError("ACL", utils.ErrCodeValidation, "unsupported protocol: "+protocol).Error()
+		log.Println("Unsupported protocol:", protocol)
+		return result
+	}
+
+	if len(ports) == 0 {
+		result.Error = utils.NewValidationError("ACL", utils.ErrCodeValidation, "no ports to check").Error()
+		log.Println("No ports to check for:", targetIP)
+		return result
+	}
+
+	newProtocolDialer := newDialer
+	if protocol == "udp" {
+		newProtocolDialer = newUDPDialer
+	}
+
+	dialer, err := newProtocolDialer(cfg)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error creating dialer:", targetIP, err)
+		return result
+	}
+	dialer.Timeout = cfg.HTTPConnectTimeout
+
+	workers := cfg.WorkerCount
+	if workers < 1 {
+		workers = 1
+	}
+
+	start := time.Now()
+
+	var (
+		wg  sync.WaitGroup
+		mu  sync.Mutex
+		sem = make(chan struct{}, workers)
+	)
+
+	for _, port := range ports {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(p int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			verdict := checkACLPort(dialer, protocol, targetIP, p, cfg.HTTPConnectTimeout)
+
+			mu.Lock()
+			result.Allowed[p] = verdict == ACLAllowed
+			switch verdict {
+			case ACLDenied:
+				result.Denied = append(result.Denied, p)
+			case ACLTimedOut:
+				result.Timedout = append(result.Timedout, p)
+			}
+			mu.Unlock()
+		}(port)
+	}
+
+	wg.Wait()
+	result.ScanDuration = time.Since(start)
+	sort.Ints(result.Denied)
+	sort.Ints(result.Timedout)
+
+	log.Println("Target:", targetIP, protocol)
+	log.Printf("Checked %d ports in %s\n", len(ports), result.ScanDuration)
+	log.Println("Denied ports:", result.Denied)
+	log.Println("Timed out ports:", result.Timedout)
+	fmt.Println("------------------------------------------------------------")
+
+	return result
+}
+
+// checkACLPort probes a single port and classifies the outcome
+func checkACLPort(dialer *net.Dialer, protocol string, ip string, port int, timeout time.Duration) string {
+	conn, err := dialer.Dial(protocol, net.JoinHostPort(ip, strconv.Itoa(port)))
+	if err != nil {
+		return aclVerdict(err)
+	}
+	defer conn.Close()
+
+	// A TCP connection is proof enough, UDP needs a round trip
+	if protocol == "tcp" {
+		return ACLAllowed
+	}
+
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return ACLTimedOut
+	}
+	if _, err := conn.Write(aclProbe); err != nil {
+		return aclVerdict(err)
+	}
+
+	buf := make([]byte, 1)
+	if _, err := conn.Read(buf); err != nil {
+		return aclVerdict(err)
+	}
+	return ACLAllowed
+}
+
+// aclVerdict classifies a dial, write or read error as denied or timed out
+func aclVerdict(err error) string {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ACLTimedOut
+	}
+	return ACLDenied
+}
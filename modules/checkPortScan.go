@@ -0,0 +1,116 @@
+package modules
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+)
+
+// Port states reported by CheckPortScan
+const (
+	PortOpen     = "Open"
+	PortClosed   = "Closed"
+	PortFiltered = "Filtered"
+)
+
+// CheckPortScan attempts a TCP connection to each port of host and classifies it as
+// Open (connected), Closed (refused or unreachable) or Filtered (timed out).
+// Connections run concurrently, bounded by cfg.WorkerCount.
+//
+// Parameters:
+//   - host: The host name or IP address to scan
+//   - ports: TCP ports to check
+//   - cfg: Configuration containing connect timeout and worker count settings
+//
+// Returns:
+//   - *PortScanTest: Pointer to PortScanTest struct containing the state of every port
+//
+// Example:
+//
+//	cfg := config.New()
+//	result := CheckPortScan("example.com", []int{22, 80, 443}, cfg)
+//	log.Println("Open ports:", result.OpenPorts)
+func CheckPortScan(host string, ports []int, cfg *config.Config) *utils.PortScanTest {
+	result := &utils.PortScanTest{
+		Host:    host,
+		Results: make(map[int]string, len(ports)),
+	}
+
+	if len(ports) == 0 {
+		result.Error = utils.NewValidationError("PortScan", utils.ErrCodeValidation, "no ports to scan").Error()
+		log.Println("No ports to scan for:", host)
+		return result
+	}
+
+	dialer, err := newDialer(cfg)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error creating dialer:", host, err)
+		return result
+	}
+	dialer.Timeout = cfg.HTTPConnectTimeout
+
+	workers := cfg.WorkerCount
+	if workers < 1 {
+		workers = 1
+	}
+
+	start := time.Now()
+
+	var (
+		wg  sync.WaitGroup
+		mu  sync.Mutex
+		sem = make(chan struct{}, workers)
+	)
+
+	for _, port := range ports {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(p int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			state := scanPort(dialer, host, p)
+
+			mu.Lock()
+			result.Results[p] = state
+			if state == PortOpen {
+				result.OpenPorts = append(result.OpenPorts, p)
+			}
+			mu.Unlock()
+		}(port)
+	}
+
+	wg.Wait()
+	result.ScanDuration = time.Since(start)
+	sort.Ints(result.OpenPorts)
+
+	log.Println("Host:", host)
+	log.Printf("Scanned %d ports in %s\n", len(ports), result.ScanDuration)
+	log.Println("Open ports:", result.OpenPorts)
+	fmt.Println("------------------------------------------------------------")
+
+	return result
+}
+
+// scanPort dials a single TCP port and classifies the outcome
+func scanPort(dialer *net.Dialer, host string, port int) string {
+	conn, err := dialer.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return PortFiltered
+		}
+		return PortClosed
+	}
+	conn.Close()
+	return PortOpen
+}
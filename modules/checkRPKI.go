@@ -0,0 +1,160 @@
+package modules
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+)
+
+// prefixOverviewAPI is the RIPE Stat endpoint reporting the origin ASes of a prefix
+const prefixOverviewAPI = "https://stat.ripe.net/data/prefix-overview/data.json"
+
+// RPKI validity states reported in RPKITest.Validity
+const (
+	RPKIValid    = "valid"
+	RPKIInvalid  = "invalid"
+	RPKINotFound = "not-found"
+)
+
+// prefixOverviewResponse is the subset of the RIPE Stat prefix overview response used by CheckRPKI
+type prefixOverviewResponse struct {
+	Data struct {
+		ASNs []struct {
+			ASN int `json:"asn"`
+		} `json:"asns"`
+	} `json:"data"`
+}
+
+// rpkiValidityResponse is the subset of the RIPE validator validity response used by CheckRPKIOrigin
+type rpkiValidityResponse struct {
+	ValidatedRoute struct {
+		Validity struct {
+			State string `json:"state"`
+			VRPs  struct {
+				Matched         []json.RawMessage `json:"matched"`
+				UnmatchedAS     []json.RawMessage `json:"unmatched_as"`
+				UnmatchedLength []json.RawMessage `json:"unmatched_length"`
+			} `json:"VRPs"`
+		} `json:"validity"`
+	} `json:"validated_route"`
+}
+
+// CheckRPKI checks the RPKI route origin validity of a prefix as announced today.
+// It looks up the AS originating prefix in RIPE Stat and validates that prefix-origin pair with
+// CheckRPKIOrigin. When several ASes originate the prefix, the first one is validated.
+//
+// Parameters:
+//   - prefix: The IP prefix to validate (e.g., "193.0.0.0/21")
+//   - cfg: Configuration containing the validator URL and timeout settings
+//
+// Returns:
+//   - *RPKITest: Pointer to RPKITest struct containing the origin AS, validity state and number of covering VRPs
+//
+// Example:
+//
+//	cfg := config.New()
+//	result := CheckRPKI("193.0.0.0/21", cfg)
+//	if result.Validity == RPKIInvalid {
+//	    log.Println("Route is RPKI invalid, it may be hijacked")
+//	}
+func CheckRPKI(prefix string, cfg *config.Config) *utils.RPKITest {
+	var overview prefixOverviewResponse
+	if err := getRPKIJSON(prefixOverviewAPI+"?resource="+url.QueryEscape(prefix), cfg, &overview); err != nil {
+		log.Println("Error looking up origin AS:", prefix, err)
+		return &utils.RPKITest{Prefix: prefix, Error: err.Error()}
+	}
+
+	if len(overview.Data.ASNs) == 0 {
+		log.Println("Prefix not announced:", prefix)
+		return &utils.RPKITest{
+			Prefix: prefix,
+			Error:  utils.NewValidationError("RPKI", utils.ErrCodeValidation, "prefix is not announced, no origin AS to validate").Error(),
+		}
+	}
+	if len(overview.Data.ASNs) > 1 {
+		log.Println("Prefix has", len(overview.Data.ASNs), "origin ASes, validating the first")
+	}
+
+	return CheckRPKIOrigin(prefix, overview.Data.ASNs[0].ASN, cfg)
+}
+
+// CheckRPKIOrigin checks the RPKI validity of prefix originated by asn against the validator at
+// cfg.RPKIValidatorURL, which must implement the RIPE validator "validity/<asn>/<prefix>" API.
+func CheckRPKIOrigin(prefix string, asn int, cfg *config.Config) *utils.RPKITest {
+	result := &utils.RPKITest{
+		Prefix: prefix,
+		ASN:    asn,
+	}
+
+	endpoint := strings.TrimSuffix(cfg.RPKIValidatorURL, "/") + "/AS" + strconv.Itoa(asn) + "/" + prefix
+
+	var validity rpkiValidityResponse
+	if err := getRPKIJSON(endpoint, cfg, &validity); err != nil {
+		result.Error = err.Error()
+		log.Println("Error querying RPKI validator:", endpoint, err)
+		return result
+	}
+
+	v := validity.ValidatedRoute.Validity
+	switch strings.ToLower(v.State) {
+	case "valid":
+		result.Validity = RPKIValid
+	case "invalid", "invalid_asn", "invalid_length":
+		result.Validity = RPKIInvalid
+	case "unknown", "notfound", "not-found", "not_found":
+		result.Validity = RPKINotFound
+	default:
+		result.Error = utils.NewParseError("RPKI", utils.ErrCodeParse, "unexpected validity state "+strconv.Quote(v.State), nil).Error()
+		log.Println("Unexpected RPKI validity state:", v.State)
+		return result
+	}
+	result.VRPCount = len(v.VRPs.Matched) + len(v.VRPs.UnmatchedAS) + len(v.VRPs.UnmatchedLength)
+
+	log.Println("Prefix:", prefix, "origin: AS"+strconv.Itoa(asn))
+	log.Println("RPKI validity:", result.Validity, "VRPs:", result.VRPCount)
+	fmt.Println("------------------------------------------------------------")
+
+	return result
+}
+
+// getRPKIJSON fetches endpoint and decodes its JSON body into v
+func getRPKIJSON(endpoint string, cfg *config.Config, v interface{}) error {
+	transport, err := newTransport(cfg)
+	if err != nil {
+		return err
+	}
+
+	client := http.Client{
+		Transport: transport,
+		Timeout:   cfg.HTTPTimeout,
+	}
+
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	setUserAgent(req, cfg)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return utils.NewNetworkError("RPKI", utils.ErrCodeHTTP, "unexpected status "+resp.Status, nil)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return utils.NewParseError("RPKI", utils.ErrCodeParse, "failed to parse response", err)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package modules
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+)
+
+// SMTPHeloName is the host name announced in the EHLO command
+const SMTPHeloName = "localhost"
+
+// CheckSMTPTLS checks whether a mail server offers STARTTLS and completes the TLS upgrade.
+// It connects to host:port, reads the greeting, sends EHLO, looks for the STARTTLS extension,
+// then upgrades the connection and records the negotiated TLS version and certificate expiry.
+// The certificate is verified against host, so an invalid certificate is reported as an error.
+//
+// Parameters:
+//   - host: The mail server host name (e.g., "smtp.gmail.com")
+//   - port: The SMTP port, usually 25 or 587
+//   - cfg: Configuration containing timeout and TLS settings
+//
+// Returns:
+//   - *SMTPTLSTest: Pointer to SMTPTLSTest struct containing reachability, STARTTLS support and TLS details
+//
+// Example:
+//
+//	cfg := config.New()
+//	result := CheckSMTPTLS("smtp.gmail.com", 587, cfg)
+//	if result.STARTTLSOffered && result.Error == "" {
+//	    log.Println("STARTTLS with", result.TLSVersion, "certificate expires", result.CertExpiry)
+//	}
+func CheckSMTPTLS(host string, port int, cfg *config.Config) *utils.SMTPTLSTest {
+	result := &utils.SMTPTLSTest{
+		Host: host,
+		Port: port,
+	}
+
+	dialer, err := newDialer(cfg)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error creating dialer:", host, err)
+		return result
+	}
+	dialer.Timeout = cfg.HTTPConnectTimeout
+
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+	conn, err := dialer.Dial("tcp", addr)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error connecting to mail server:", addr, err)
+		fmt.Println("------------------------------------------------------------")
+		return result
+	}
+
+	// One deadline covers the whole SMTP conversation including the TLS handshake
+	if err := conn.SetDeadline(time.Now().Add(cfg.HTTPTimeout)); err != nil {
+		conn.Close()
+		result.Error = err.Error()
+		return result
+	}
+
+	// NewClient reads the 220 greeting
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		result.Error = err.Error()
+		log.Println("Error reading SMTP greeting:", addr, err)
+		fmt.Println("------------------------------------------------------------")
+		return result
+	}
+	defer client.Close()
+	result.Reachable = true
+
+	if err := client.Hello(SMTPHeloName); err != nil {
+		result.Error = err.Error()
+		log.Println("EHLO failed:", addr, err)
+		fmt.Println("------------------------------------------------------------")
+		return result
+	}
+
+	result.STARTTLSOffered, _ = client.Extension("STARTTLS")
+	if !result.STARTTLSOffered {
+		result.Error = utils.NewValidationError("SMTP", utils.ErrCodeValidation, "server does not offer STARTTLS").Error()
+		log.Println("STARTTLS not offered:", addr)
+		fmt.Println("------------------------------------------------------------")
+		return result
+	}
+
+	if err := client.StartTLS(&tls.Config{
+		ServerName: host,
+		MinVersion: cfg.TLSMinVersion,
+		MaxVersion: cfg.TLSMaxVersion,
+	}); err != nil {
+		result.Error = utils.NewNetworkError("SMTP", utils.ClassifyError(err), "STARTTLS failed", err).Error()
+		log.Println("STARTTLS failed:", addr, err)
+		fmt.Println("------------------------------------------------------------")
+		return result
+	}
+
+	if state, ok := client.TLSConnectionState(); ok {
+		result.TLSVersion = utils.TLSVersionName(state.Version)
+		if len(state.PeerCertificates) > 0 {
+			result.CertExpiry = state.PeerCertificates[0].NotAfter
+		}
+	}
+
+	// Politely end the session; failures here do not affect the result
+	client.Quit()
+
+	log.Println("Mail server:", addr)
+	log.Println("STARTTLS offered:", result.STARTTLSOffered)
+	log.Println("TLS version:", result.TLSVersion)
+	log.Println("Certificate expires:", result.CertExpiry)
+	fmt.Println("------------------------------------------------------------")
+
+	return result
+}
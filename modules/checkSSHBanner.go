@@ -0,0 +1,130 @@
+package modules
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+)
+
+const (
+	// DefaultSSHPort is the standard SSH port
+	DefaultSSHPort = 22
+
+	// sshMaxBannerLines bounds the lines a server may send before its identification string
+	sshMaxBannerLines = 20
+
+	// sshMaxLineLength is the maximum identification string length allowed by RFC 4253
+	sshMaxLineLength = 255
+)
+
+// CheckSSHBanner connects to an SSH server and reads its identification string without authenticating.
+// Per RFC 4253 section 4.2 the identification string has the form
+// "SSH-protoversion-softwareversion SP comments CR LF" and may be preceded by other lines.
+//
+// Parameters:
+//   - host: The SSH server host name or IP address
+//   - port: The SSH port (0 uses DefaultSSHPort)
+//   - cfg: Configuration containing timeout and local address settings
+//
+// Returns:
+//   - *SSHBannerTest: Pointer to SSHBannerTest struct containing the banner, protocol version and server software
+//
+// Example:
+//
+//	cfg := config.New()
+//	result := CheckSSHBanner("example.com", 22, cfg)
+//	if result.Error == "" {
+//	    log.Println("SSH", result.SSHVersion, "server:", result.ServerSoftware)
+//	}
+func CheckSSHBanner(host string, port int, cfg *config.Config) *utils.SSHBannerTest {
+	if port == 0 {
+		port = DefaultSSHPort
+	}
+
+	result := &utils.SSHBannerTest{
+		Host: host,
+		Port: port,
+	}
+
+	dialer, err := newDialer(cfg)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error creating dialer:", host, err)
+		return result
+	}
+	dialer.Timeout = cfg.HTTPConnectTimeout
+
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+	start := time.Now()
+	conn, err := dialer.Dial("tcp", addr)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error connecting to SSH server:", addr, err)
+		fmt.Println("------------------------------------------------------------")
+		return result
+	}
+	defer conn.Close()
+
+	if err := conn.SetReadDeadline(time.Now().Add(cfg.HTTPTimeout)); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	reader := bufio.NewReaderSize(conn, sshMaxLineLength+1)
+	for i := 0; i < sshMaxBannerLines && result.Banner == ""; i++ {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			result.Error = err.Error()
+			log.Println("Error reading SSH banner:", addr, err)
+			fmt.Println("------------------------------------------------------------")
+			return result
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		if strings.HasPrefix(line, "SSH-") {
+			result.Banner = line
+		}
+	}
+	result.RTT = time.Since(start)
+
+	if result.Banner == "" {
+		result.Error = utils.NewParseError("SSH", utils.ErrCodeParse, "no SSH identification string received", nil).Error()
+		log.Println("No SSH identification string from:", addr)
+		return result
+	}
+
+	version, software, err := parseSSHBanner(result.Banner)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error parsing SSH banner:", addr, err)
+		return result
+	}
+	result.SSHVersion = version
+	result.ServerSoftware = software
+
+	log.Println("SSH server:", addr)
+	log.Println("Banner:", result.Banner)
+	log.Println("Protocol version:", result.SSHVersion, "software:", result.ServerSoftware)
+	log.Println("RTT:", result.RTT)
+	fmt.Println("------------------------------------------------------------")
+
+	return result
+}
+
+// parseSSHBanner splits an identification string into its protocol and software versions.
+// The optional comments after the first space are dropped.
+func parseSSHBanner(banner string) (version string, software string, err error) {
+	ident, _, _ := strings.Cut(banner, " ")
+	parts := strings.SplitN(ident, "-", 3)
+	if len(parts) != 3 || parts[0] != "SSH" || parts[1] == "" || parts[2] == "" {
+		return "", "", utils.NewParseError("SSH", utils.ErrCodeParse, "malformed SSH identification string: "+banner, nil)
+	}
+	return parts[1], parts[2], nil
+}
@@ -0,0 +1,111 @@
+package modules
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+)
+
+// CheckSubdomainEnum resolves "sub.domain" for every sub in wordlist and reports those that resolve.
+// Lookups run concurrently, bounded by cfg.WorkerCount. When wordlist is empty it is loaded
+// from cfg.SubdomainWordlistPath.
+//
+// Parameters:
+//   - domain: The parent domain to enumerate (e.g., "example.com")
+//   - wordlist: Subdomain labels to try (e.g., "www", "mail", "api")
+//   - cfg: Configuration containing resolver, worker count and timeout settings
+//
+// Returns:
+//   - *SubdomainEnumTest: Pointer to SubdomainEnumTest struct containing the discovered subdomains
+//
+// Example:
+//
+//	cfg := config.New()
+//	result := CheckSubdomainEnum("example.com", []string{"www", "mail"}, cfg)
+//	for _, found := range result.FoundSubdomains {
+//	    log.Println(found.Subdomain, found.IPs)
+//	}
+func CheckSubdomainEnum(domain string, wordlist []string, cfg *config.Config) *utils.SubdomainEnumTest {
+	result := &utils.SubdomainEnumTest{
+		Domain: domain,
+	}
+
+	if len(wordlist) == 0 && cfg.SubdomainWordlistPath != "" {
+		words, err := utils.LoadWordlist(cfg.SubdomainWordlistPath)
+		if err != nil {
+			result.Error = err.Error()
+			log.Println("Error loading subdomain wordlist:", err)
+			return result
+		}
+		wordlist = words
+	}
+
+	if len(wordlist) == 0 {
+		result.Error = utils.NewValidationError("SubdomainEnum", utils.ErrCodeValidation, "wordlist is empty").Error()
+		log.Println("Subdomain wordlist is empty")
+		return result
+	}
+
+	workers := cfg.WorkerCount
+	if workers < 1 {
+		workers = 1
+	}
+
+	resolver := newResolver(cfg.DNSResolver, cfg.DNSTimeout)
+	start := time.Now()
+
+	var (
+		wg  sync.WaitGroup
+		mu  sync.Mutex
+		sem = make(chan struct{}, workers)
+	)
+
+	for _, word := range wordlist {
+		sub := strings.TrimSuffix(strings.TrimSpace(word), ".")
+		if sub == "" {
+			continue
+		}
+		result.TestedCount++
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(host string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			ips, err := lookupIPv4(resolver, host, cfg.DNSTimeout)
+			if err != nil || len(ips) == 0 {
+				return
+			}
+
+			mu.Lock()
+			result.FoundSubdomains = append(result.FoundSubdomains, utils.SubdomainResult{
+				Subdomain: host,
+				IPs:       ips,
+			})
+			mu.Unlock()
+		}(sub + "." + domain)
+	}
+
+	wg.Wait()
+	result.ScanDuration = time.Since(start)
+
+	sort.Slice(result.FoundSubdomains, func(i, j int) bool {
+		return result.FoundSubdomains[i].Subdomain < result.FoundSubdomains[j].Subdomain
+	})
+
+	log.Println("Domain:", domain)
+	log.Printf("Found %d of %d subdomains in %s\n", len(result.FoundSubdomains), result.TestedCount, result.ScanDuration)
+	for _, found := range result.FoundSubdomains {
+		log.Println("Subdomain:", found.Subdomain, found.IPs)
+	}
+	fmt.Println("------------------------------------------------------------")
+
+	return result
+}
@@ -0,0 +1,125 @@
+package modules
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+)
+
+const (
+	// KeepaliveProbeInterval is the TCP keepalive period used by CheckTCPKeepalive
+	KeepaliveProbeInterval = 10 * time.Second
+
+	// keepaliveReadWait is how long CheckTCPKeepalive waits for the peer to react
+	keepaliveReadWait = time.Second
+)
+
+// keepaliveProbe is the data sent after the idle period
+var keepaliveProbe = []byte("\r\n")
+
+// CheckTCPKeepalive checks whether a TCP connection with keepalive enabled survives an idle period.
+// It connects to host:port, leaves the connection idle for cfg.KeepaliveIdle, then sends a small
+// probe. A connection reset means a middlebox such as a NAT dropped the connection state while it
+// was idle; an answer, a clean close or silence means the connection is still usable.
+//
+// Parameters:
+//   - host: The host name or IP address to connect to
+//   - port: The TCP port to connect to
+//   - cfg: Configuration containing the idle period, connect timeout and local address settings
+//
+// Returns:
+//   - *KeepaliveTest: Pointer to KeepaliveTest struct reporting whether the connection survived
+//
+// Example:
+//
+//	cfg := config.New()
+//	cfg.KeepaliveIdle = 5 * time.Minute
+//	result := CheckTCPKeepalive("example.com", 443, cfg)
+//	if result.Error == "" && !result.ConnectionSurvived {
+//	    log.Println("Idle connections are dropped, check NAT timeouts")
+//	}
+func CheckTCPKeepalive(host string, port int, cfg *config.Config) *utils.KeepaliveTest {
+	result := &utils.KeepaliveTest{
+		Host:         host,
+		Port:         port,
+		IdleDuration: cfg.KeepaliveIdle,
+	}
+
+	dialer, err := newDialer(cfg)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error creating dialer:", host, err)
+		return result
+	}
+	dialer.Timeout = cfg.HTTPConnectTimeout
+	dialer.KeepAlive = KeepaliveProbeInterval
+
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+	conn, err := dialer.Dial("tcp", addr)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Error connecting:", addr, err)
+		fmt.Println("------------------------------------------------------------")
+		return result
+	}
+	defer conn.Close()
+	result.KeepaliveEnabled = true
+
+	log.Printf("Connected to %s, idling for %s\n", addr, cfg.KeepaliveIdle)
+	time.Sleep(cfg.KeepaliveIdle)
+
+	result.ConnectionSurvived = keepaliveAlive(conn)
+
+	log.Println("Host:", addr)
+	log.Println("Idle duration:", result.IdleDuration)
+	log.Println("Connection survived:", result.ConnectionSurvived)
+	fmt.Println("------------------------------------------------------------")
+
+	return result
+}
+
+// keepaliveAlive reports whether conn is still usable after being idle
+func keepaliveAlive(conn net.Conn) bool {
+	buf := make([]byte, 512)
+
+	// Anything already pending tells whether the peer closed or reset the connection while idle
+	if !keepaliveReadOK(conn, buf, false) {
+		return false
+	}
+
+	if err := conn.SetWriteDeadline(time.Now().Add(keepaliveReadWait)); err != nil {
+		return false
+	}
+	if _, err := conn.Write(keepaliveProbe); err != nil {
+		return false
+	}
+
+	// After the probe a clean close is the peer's answer, only a reset means the state was lost
+	return keepaliveReadOK(conn, buf, true)
+}
+
+// keepaliveReadOK reads briefly from conn and reports whether the outcome shows a live connection.
+// Data and timeouts are fine; EOF is fine only when eofOK is set.
+func keepaliveReadOK(conn net.Conn, buf []byte, eofOK bool) bool {
+	if err := conn.SetReadDeadline(time.Now().Add(keepaliveReadWait)); err != nil {
+		return false
+	}
+
+	_, err := conn.Read(buf)
+	if err == nil {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return eofOK && errors.Is(err, io.EOF)
+}
@@ -0,0 +1,96 @@
+package modules
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+)
+
+// TorExitListZone is the DNS zone of the Tor Project's IP and port based exit list
+const TorExitListZone = "ip-port.exitlist.torproject.org"
+
+// CheckTorExitNode checks whether an IPv4 address is a Tor exit node.
+// It queries the Tor Project's DNS exit list for
+// {reversed-ip}.{port}.{reversed-dest-ip}.ip-port.exitlist.torproject.org, which answers with
+// 127.0.0.2 when ip is an exit allowed to connect to cfg.TorExitListDestIP on cfg.TorExitListPort
+// and with NXDOMAIN otherwise.
+//
+// Parameters:
+//   - ip: The IPv4 address to check (e.g., the external IP found by CheckVPN)
+//   - cfg: Configuration containing the destination port and address, DNS resolver and timeout settings
+//
+// Returns:
+//   - *TorExitTest: Pointer to TorExitTest struct containing whether ip is an exit, the query time and any errors
+//
+// Example:
+//
+//	cfg := config.New()
+//	result := CheckTorExitNode("185.220.101.1", cfg)
+//	if result.IsTorExit {
+//	    log.Println("Traffic comes from the Tor network")
+//	}
+func CheckTorExitNode(ip string, cfg *config.Config) *utils.TorExitTest {
+	result := &utils.TorExitTest{
+		IP:          ip,
+		CheckedPort: cfg.TorExitListPort,
+	}
+
+	name, err := torExitListName(ip, cfg.TorExitListPort, cfg.TorExitListDestIP)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println("Invalid Tor exit list query:", ip, err)
+		return result
+	}
+
+	resolver := newResolver(cfg.DNSResolver, cfg.DNSTimeout)
+
+	start := time.Now()
+	addrs, err := lookupIPv4(resolver, name, cfg.DNSTimeout)
+	result.QueryRTT = time.Since(start)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+			result.Error = utils.NewNetworkError("TorExit", utils.ErrCodeDNS, "Tor exit list query failed", err).Error()
+			log.Println("Error querying Tor exit list:", name, err)
+			return result
+		}
+	}
+
+	for _, addr := range addrs {
+		if addr == "127.0.0.2" {
+			result.IsTorExit = true
+		}
+	}
+
+	log.Println("IP:", ip)
+	log.Println("Checked destination:", net.JoinHostPort(cfg.TorExitListDestIP, strconv.Itoa(cfg.TorExitListPort)))
+	log.Println("Tor exit:", result.IsTorExit)
+	log.Println("Query RTT:", result.QueryRTT)
+	fmt.Println("------------------------------------------------------------")
+
+	return result
+}
+
+// torExitListName builds the exit list query name of ip exiting to destIP:port
+func torExitListName(ip string, port int, destIP string) (string, error) {
+	src := net.ParseIP(ip).To4()
+	if src == nil {
+		return "", utils.NewValidationError("TorExit", utils.ErrCodeValidation, "not an IPv4 address: "+ip)
+	}
+	dst := net.ParseIP(destIP).To4()
+	if dst == nil {
+		return "", utils.NewValidationError("TorExit", utils.ErrCodeValidation, "destination is not an IPv4 address: "+destIP)
+	}
+	if port < 1 || port > 65535 {
+		return "", utils.NewValidationError("TorExit", utils.ErrCodeValidation, "invalid destination port: "+strconv.Itoa(port))
+	}
+
+	return fmt.Sprintf("%d.%d.%d.%d.%d.%d.%d.%d.%d.%s",
+		src[3], src[2], src[1], src[0], port, dst[3], dst[2], dst[1], dst[0], TorExitListZone), nil
+}
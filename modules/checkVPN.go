@@ -1,14 +1,19 @@
 package modules
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log"
 	"net"
 	"net/http"
 	"regexp"
+	"strings"
+	"time"
 
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
 	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const (
@@ -22,6 +27,8 @@ const (
 // Parameters:
 //   - ipChecker: URL of an IP detection service (e.g., "http://checkip.dyndns.org/")
 //
+// CheckVPN uses the default configuration; use CheckVPNContext to pass one.
+//
 // Returns:
 //   - *VPNTest: Pointer to VPNTest struct containing detection status and any errors
 //
@@ -32,19 +39,50 @@ const (
 //	    log.Println("VPN Status:", result.Status)
 //	}
 func CheckVPN(ipChecker string) *utils.VPNTest {
+	return CheckVPNContext(context.Background(), ipChecker, config.New())
+}
+
+// CheckVPNContext is like CheckVPN but records a trace span as a child of ctx,
+// cancels the IP lookup when ctx is done and sends the User-Agent from cfg.
+func CheckVPNContext(ctx context.Context, ipChecker string, cfg *config.Config) *utils.VPNTest {
 	result := &utils.VPNTest{}
 
-	resp, err := http.Get(ipChecker)
+	start := time.Now()
+	ctx, span := startSpan(ctx, "CheckVPN", attribute.String("url", ipChecker))
+	defer func() { endSpan(span, start, result.Error) }()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ipChecker, nil)
+	if err != nil {
+		result.Error = err.Error()
+		result.ErrorCode = utils.ClassifyError(err)
+		fmt.Println(err)
+		return result
+	}
+	setUserAgent(req, cfg)
+
+	transport, err := newTransport(cfg)
 	if err != nil {
 		result.Error = err.Error()
+		result.ErrorCode = utils.ClassifyError(err)
+		fmt.Println(err)
+		return result
+	}
+
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		result.Error = err.Error()
+		result.ErrorCode = utils.ClassifyError(err)
 		fmt.Println(err)
 		return result
 	}
 	defer resp.Body.Close()
 
+	span.SetAttributes(attribute.Int("status_code", resp.StatusCode))
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		result.Error = err.Error()
+		result.ErrorCode = utils.ClassifyError(err)
 		fmt.Println(err)
 		return result
 	}
@@ -54,25 +92,33 @@ func CheckVPN(ipChecker string) *utils.VPNTest {
 	matches := re.FindStringSubmatch(string(body))
 	if len(matches) < 2 {
 		result.Error = "could not extract IP address from response"
+		result.ErrorCode = utils.ErrCodeParse
 		log.Println("Could not extract IP address from response")
 		fmt.Println("------------------------------------------------------------")
 		return result
 	}
 
 	externalIP := matches[1]
+	result.ExternalIP = externalIP
 
 	// Validate IP address format
 	if net.ParseIP(externalIP) == nil {
 		result.Error = "invalid IP address extracted: " + externalIP
+		result.ErrorCode = utils.ErrCodeParse
 		log.Println("Invalid IP address extracted:", externalIP)
 		fmt.Println("------------------------------------------------------------")
 		return result
 	}
 
+	if cfg.EnableGeolocation {
+		result.Geo = CheckIPGeolocation(externalIP, cfg)
+	}
+
 	// Get local IP
 	localIPs, err := net.LookupHost("localhost")
 	if err != nil {
 		result.Error = err.Error()
+		result.ErrorCode = utils.ClassifyError(err)
 		log.Println("Error getting local IP:", err)
 		fmt.Println("------------------------------------------------------------")
 		return result
@@ -80,6 +126,7 @@ func CheckVPN(ipChecker string) *utils.VPNTest {
 
 	if len(localIPs) == 0 {
 		result.Error = "no local IP addresses found"
+		result.ErrorCode = utils.ErrCodeDNS
 		log.Println("No local IP addresses found")
 		fmt.Println("------------------------------------------------------------")
 		return result
@@ -93,6 +140,72 @@ func CheckVPN(ipChecker string) *utils.VPNTest {
 		log.Println("Using VPN or proxy.")
 	}
 
+	if hasIPv6() {
+		checkIPv6Exit(ctx, result, cfg)
+	}
+
 	fmt.Println("------------------------------------------------------------")
 	return result
 }
+
+// hasIPv6 reports whether a local interface has a global IPv6 address
+func hasIPv6() bool {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return false
+	}
+
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.To4() != nil || ipNet.IP.IsLoopback() || ipNet.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// checkIPv6Exit asks cfg.IPv6Checker for the external IPv6 address and sets ExternalIPv6 and IPv6Status.
+// IPv6 addresses are normally not translated, so an external address that is not assigned to a
+// local interface means IPv6 traffic leaves through a VPN or proxy. Failures are logged and leave both fields empty.
+func checkIPv6Exit(ctx context.Context, result *utils.VPNTest, cfg *config.Config) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cfg.IPv6Checker, nil)
+	if err != nil {
+		log.Println("Error creating IPv6 request:", cfg.IPv6Checker, err)
+		return
+	}
+	setUserAgent(req, cfg)
+
+	transport, err := newTransport(cfg)
+	if err != nil {
+		log.Println("Error creating transport:", cfg.IPv6Checker, err)
+		return
+	}
+
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		log.Println("Error getting external IPv6:", cfg.IPv6Checker, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 256))
+	if err != nil {
+		log.Println("Error reading external IPv6:", cfg.IPv6Checker, err)
+		return
+	}
+
+	ip := net.ParseIP(strings.TrimSpace(string(body)))
+	if ip == nil || ip.To4() != nil {
+		log.Println("Invalid external IPv6 address:", strings.TrimSpace(string(body)))
+		return
+	}
+
+	result.ExternalIPv6 = ip.String()
+	if utils.ValidateLocalAddr(result.ExternalIPv6) == nil {
+		result.IPv6Status = "Not using VPN or proxy."
+	} else {
+		result.IPv6Status = "Using VPN or proxy."
+	}
+	log.Println("External IPv6:", result.ExternalIPv6, result.IPv6Status)
+}
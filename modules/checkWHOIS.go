@@ -0,0 +1,211 @@
+package modules
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+)
+
+const (
+	// WHOISPort is the TCP port of the WHOIS protocol (RFC 3912)
+	WHOISPort = "43"
+
+	// IANAWHOISServer is queried to find the WHOIS server of TLDs missing from WHOISServers
+	IANAWHOISServer = "whois.iana.org"
+
+	// maxWHOISResponse caps how much of a WHOIS response is read
+	maxWHOISResponse = 1 << 20
+)
+
+// WHOISServers maps common TLDs to their registry WHOIS servers
+var WHOISServers = map[string]string{
+	"com":  "whois.verisign-grs.com",
+	"net":  "whois.verisign-grs.com",
+	"org":  "whois.pir.org",
+	"info": "whois.afilias.net",
+	"io":   "whois.nic.io",
+	"dev":  "whois.nic.google",
+	"app":  "whois.nic.google",
+	"ir":   "whois.nic.ir",
+	"uk":   "whois.nic.uk",
+	"de":   "whois.denic.de",
+	"fr":   "whois.nic.fr",
+	"nl":   "whois.domain-registry.nl",
+	"eu":   "whois.eu",
+	"us":   "whois.nic.us",
+	"co":   "whois.nic.co",
+	"me":   "whois.nic.me",
+}
+
+// whoisDateLayouts are the date formats commonly used by WHOIS servers
+var whoisDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05Z",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"02-Jan-2006",
+	"2006.01.02",
+	"2006/01/02",
+}
+
+// CheckWHOIS queries the registry WHOIS server of a domain and parses the registrar, dates and status.
+// The server is taken from WHOISServers or, for other TLDs, from the referral returned by whois.iana.org.
+//
+// Parameters:
+//   - domain: The registered domain to look up (e.g., "example.com")
+//   - cfg: Configuration containing timeout settings
+//
+// Returns:
+//   - *WHOISTest: Pointer to WHOISTest struct containing the raw response, parsed fields and any errors
+//
+// Example:
+//
+//	cfg := config.New()
+//	result := CheckWHOIS("example.com", cfg)
+//	if result.Error == "" {
+//	    log.Printf("%s expires in %d days\n", result.Domain, result.DaysUntilExpiry)
+//	}
+func CheckWHOIS(domain string, cfg *config.Config) *utils.WHOISTest {
+	result := &utils.WHOISTest{
+		Domain: domain,
+	}
+
+	domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+	tld := domain[strings.LastIndex(domain, ".")+1:]
+
+	server, ok := WHOISServers[tld]
+	if !ok {
+		referral, err := queryWHOIS(IANAWHOISServer, tld, cfg)
+		if err != nil {
+			result.Error = err.Error()
+			log.Printf("WHOIS referral lookup failed for %s: %v\n", tld, err)
+			fmt.Println("------------------------------------------------------------")
+			return result
+		}
+
+		server = whoisField(referral, "refer", "whois")
+		if server == "" {
+			result.Error = utils.NewValidationError("WHOIS", utils.ErrCodeValidation, "no WHOIS server known for TLD "+tld).Error()
+			log.Println("No WHOIS server known for TLD:", tld)
+			fmt.Println("------------------------------------------------------------")
+			return result
+		}
+	}
+
+	response, err := queryWHOIS(server, domain, cfg)
+	if err != nil {
+		result.Error = err.Error()
+		log.Printf("WHOIS query to %s failed for %s: %v\n", server, domain, err)
+		fmt.Println("------------------------------------------------------------")
+		return result
+	}
+
+	result.RawResponse = response
+	result.Registrar = whoisField(response, "Registrar", "registrar", "Sponsoring Registrar")
+	result.CreationDate = parseWHOISDate(whoisField(response, "Creation Date", "created", "Registered on", "Registration Time"))
+	result.ExpiryDate = parseWHOISDate(whoisField(response,
+		"Registry Expiry Date", "Registrar Registration Expiration Date", "Expiration Date", "Expiry Date", "expire-date", "paid-till", "expires"))
+	result.Status = whoisFields(response, "Domain Status", "status")
+
+	if !result.ExpiryDate.IsZero() {
+		result.DaysUntilExpiry = int(time.Until(result.ExpiryDate).Hours() / 24)
+	}
+
+	log.Println("Domain:", domain)
+	log.Println("WHOIS server:", server)
+	log.Println("Registrar:", result.Registrar)
+	log.Println("Created:", result.CreationDate)
+	log.Println("Expires:", result.ExpiryDate, "days left:", result.DaysUntilExpiry)
+	log.Println("Status:", result.Status)
+	fmt.Println("------------------------------------------------------------")
+
+	return result
+}
+
+// queryWHOIS sends a query to a WHOIS server and returns the full response
+func queryWHOIS(server, query string, cfg *config.Config) (string, error) {
+	dialer, err := newDialer(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	conn, err := dialer.Dial("tcp", net.JoinHostPort(server, WHOISPort))
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(cfg.HTTPTimeout)); err != nil {
+		return "", err
+	}
+
+	if _, err := fmt.Fprintf(conn, "%s\r\n", query); err != nil {
+		return "", err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(conn, maxWHOISResponse))
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// whoisField returns the value of the first line whose key matches any of keys (case-insensitive)
+func whoisField(response string, keys ...string) string {
+	values := whoisFields(response, keys...)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// whoisFields returns the values of all lines whose key matches any of keys (case-insensitive)
+func whoisFields(response string, keys ...string) []string {
+	var values []string
+
+	scanner := bufio.NewScanner(strings.NewReader(response))
+	for scanner.Scan() {
+		key, value, found := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !found {
+			continue
+		}
+
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		for _, k := range keys {
+			if strings.EqualFold(strings.TrimSpace(key), k) {
+				values = append(values, value)
+				break
+			}
+		}
+	}
+
+	return values
+}
+
+// parseWHOISDate parses a WHOIS date, returning the zero time when no layout matches
+func parseWHOISDate(value string) time.Time {
+	// Some servers append a timezone name or comment after the date
+	if fields := strings.Fields(value); len(fields) > 0 && !strings.Contains(value, ":") {
+		value = fields[0]
+	}
+
+	for _, layout := range whoisDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
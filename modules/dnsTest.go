@@ -0,0 +1,74 @@
+package modules
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// CheckDNS resolves a domain and measures how long the resolution took.
+// It uses cfg.DNSResolver when set and the system resolver otherwise.
+//
+// Parameters:
+//   - domain: The domain name to resolve
+//   - cfg: Configuration containing resolver and timeout settings
+//
+// Returns:
+//   - *DNSTest: Pointer to DNSTest struct containing the resolved addresses and resolution time
+//
+// Example:
+//
+//	cfg := config.New()
+//	result := CheckDNS("example.com", cfg)
+//	if result.Error == "" {
+//	    log.Println("Resolved:", result.IPs, "in", result.ResolutionTime)
+//	}
+func CheckDNS(domain string, cfg *config.Config) *utils.DNSTest {
+	return CheckDNSContext(context.Background(), domain, cfg)
+}
+
+// CheckDNSContext is like CheckDNS but records a trace span as a child of ctx
+// and cancels the lookup when ctx is done.
+func CheckDNSContext(ctx context.Context, domain string, cfg *config.Config) *utils.DNSTest {
+	result := &utils.DNSTest{
+		Domain:   domain,
+		Resolver: cfg.DNSResolver,
+	}
+
+	if result.Resolver == "" {
+		result.Resolver = "system"
+	}
+
+	start := time.Now()
+	ctx, span := startSpan(ctx, "CheckDNS", attribute.String("domain", domain))
+	defer func() { endSpan(span, start, result.Error) }()
+
+	warnBypassesProxy("DNS test", cfg)
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.DNSTimeout)
+	defer cancel()
+
+	addrs, err := newResolver(cfg.DNSResolver, cfg.DNSTimeout).LookupHost(ctx, domain)
+	result.ResolutionTime = time.Since(start)
+	if err != nil {
+		result.Error = err.Error()
+		result.ErrorCode = utils.ClassifyError(err)
+		log.Printf("DNS lookup failed for %s: %v\n", domain, err)
+		fmt.Println("------------------------------------------------------------")
+		return result
+	}
+
+	result.IPs = addrs
+
+	log.Println("Domain:", domain)
+	log.Println("Resolved IPs:", addrs)
+	log.Println("Resolution time:", result.ResolutionTime)
+	fmt.Println("------------------------------------------------------------")
+
+	return result
+}
@@ -0,0 +1,117 @@
+package modules
+
+import (
+	"net"
+	"strings"
+	"time"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// buildDNSQuery encodes a recursive DNS query for domain in wire format
+func buildDNSQuery(id uint16, domain string, qtype dnsmessage.Type) ([]byte, error) {
+	return buildDNSMessage(dnsmessage.Header{ID: id, RecursionDesired: true}, domain, qtype)
+}
+
+// buildDNSMessage encodes a DNS query for domain with the given header in wire format
+func buildDNSMessage(header dnsmessage.Header, domain string, qtype dnsmessage.Type) ([]byte, error) {
+	if !strings.HasSuffix(domain, ".") {
+		domain += "."
+	}
+
+	name, err := dnsmessage.NewName(domain)
+	if err != nil {
+		return nil, utils.NewValidationError("DNS", utils.ErrCodeValidation, "invalid domain name: "+domain)
+	}
+
+	builder := dnsmessage.NewBuilder(nil, header)
+	builder.EnableCompression()
+	if err := builder.StartQuestions(); err != nil {
+		return nil, err
+	}
+	if err := builder.Question(dnsmessage.Question{
+		Name:  name,
+		Type:  qtype,
+		Class: dnsmessage.ClassINET,
+	}); err != nil {
+		return nil, err
+	}
+
+	return builder.Finish()
+}
+
+// exchangeDNS sends a wire format query to server over UDP and returns the raw response
+func exchangeDNS(dialer *net.Dialer, server string, query []byte, timeout time.Duration) ([]byte, error) {
+	conn, err := dialer.Dial("udp", server)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+
+	if _, err := conn.Write(query); err != nil {
+		return nil, err
+	}
+
+	// Large enough for any UDP DNS response
+	response := make([]byte, 65535)
+	n, err := conn.Read(response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response[:n], nil
+}
+
+// parseDNSAddrs extracts the A and AAAA addresses from the answer section of a wire format response
+func parseDNSAddrs(msg []byte) ([]string, error) {
+	var parser dnsmessage.Parser
+	header, err := parser.Start(msg)
+	if err != nil {
+		return nil, utils.NewParseError("DNS", utils.ErrCodeParse, "failed to parse DNS response header", err)
+	}
+
+	if header.RCode != dnsmessage.RCodeSuccess {
+		return nil, utils.NewNetworkError("DNS", utils.ErrCodeDNS, "DNS server returned "+header.RCode.String(), nil)
+	}
+
+	if err := parser.SkipAllQuestions(); err != nil {
+		return nil, utils.NewParseError("DNS", utils.ErrCodeParse, "failed to skip DNS questions", err)
+	}
+
+	var addrs []string
+	for {
+		h, err := parser.AnswerHeader()
+		if err == dnsmessage.ErrSectionDone {
+			break
+		}
+		if err != nil {
+			return nil, utils.NewParseError("DNS", utils.ErrCodeParse, "failed to parse DNS answer", err)
+		}
+
+		switch h.Type {
+		case dnsmessage.TypeA:
+			r, err := parser.AResource()
+			if err != nil {
+				return nil, utils.NewParseError("DNS", utils.ErrCodeParse, "failed to parse A record", err)
+			}
+			addrs = append(addrs, net.IP(r.A[:]).String())
+		case dnsmessage.TypeAAAA:
+			r, err := parser.AAAAResource()
+			if err != nil {
+				return nil, utils.NewParseError("DNS", utils.ErrCodeParse, "failed to parse AAAA record", err)
+			}
+			addrs = append(addrs, net.IP(r.AAAA[:]).String())
+		default:
+			if err := parser.SkipAnswer(); err != nil {
+				return nil, utils.NewParseError("DNS", utils.ErrCodeParse, "failed to skip DNS answer", err)
+			}
+		}
+	}
+
+	return addrs, nil
+}
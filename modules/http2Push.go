@@ -0,0 +1,144 @@
+package modules
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/hpack"
+)
+
+// countHTTP2Pushes requests target over a raw HTTP/2 connection that allows server push and counts
+// the PUSH_PROMISE frames received until the response is complete.
+//
+// Go's HTTP/2 client disables server push in its SETTINGS frame, so servers never push to it;
+// this separate request is the only way to see pushes. Pushed streams are read and discarded.
+func countHTTP2Pushes(ctx context.Context, target *url.URL, cfg *config.Config) (int, error) {
+	if target.Scheme != "https" {
+		return 0, errors.New("server push detection requires https")
+	}
+
+	dialer, err := newDialer(cfg)
+	if err != nil {
+		return 0, err
+	}
+
+	host := target.Hostname()
+	addr := target.Host
+	if target.Port() == "" {
+		addr = net.JoinHostPort(host, "443")
+	}
+
+	rawConn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return 0, err
+	}
+	conn := tls.Client(rawConn, &tls.Config{
+		ServerName: host,
+		NextProtos: []string{http2.NextProtoTLS},
+		MinVersion: cfg.TLSMinVersion,
+		MaxVersion: cfg.TLSMaxVersion,
+	})
+	defer conn.Close()
+
+	if err := conn.HandshakeContext(ctx); err != nil {
+		return 0, err
+	}
+	if conn.ConnectionState().NegotiatedProtocol != http2.NextProtoTLS {
+		return 0, errors.New("server did not negotiate HTTP/2")
+	}
+	if err := conn.SetDeadline(time.Now().Add(cfg.HTTPTimeout)); err != nil {
+		return 0, err
+	}
+
+	if _, err := conn.Write([]byte(http2.ClientPreface)); err != nil {
+		return 0, err
+	}
+	framer := http2.NewFramer(conn, conn)
+	if err := framer.WriteSettings(http2.Setting{ID: http2.SettingEnablePush, Val: 1}); err != nil {
+		return 0, err
+	}
+
+	var headers bytes.Buffer
+	encoder := hpack.NewEncoder(&headers)
+	fields := []hpack.HeaderField{
+		{Name: ":method", Value: "GET"},
+		{Name: ":scheme", Value: "https"},
+		{Name: ":authority", Value: target.Host},
+		{Name: ":path", Value: target.RequestURI()},
+	}
+	if cfg.UserAgent != "" {
+		fields = append(fields, hpack.HeaderField{Name: "user-agent", Value: cfg.UserAgent})
+	}
+	for _, f := range fields {
+		if err := encoder.WriteField(f); err != nil {
+			return 0, err
+		}
+	}
+
+	const streamID = 1
+	if err := framer.WriteHeaders(http2.HeadersFrameParam{
+		StreamID:      streamID,
+		BlockFragment: headers.Bytes(),
+		EndStream:     true,
+		EndHeaders:    true,
+	}); err != nil {
+		return 0, err
+	}
+
+	pushes := 0
+	for {
+		frame, err := framer.ReadFrame()
+		if err != nil {
+			return pushes, err
+		}
+
+		switch f := frame.(type) {
+		case *http2.SettingsFrame:
+			if !f.IsAck() {
+				if err := framer.WriteSettingsAck(); err != nil {
+					return pushes, err
+				}
+			}
+		case *http2.PingFrame:
+			if !f.IsAck() {
+				if err := framer.WritePing(true, f.Data); err != nil {
+					return pushes, err
+				}
+			}
+		case *http2.PushPromiseFrame:
+			pushes++
+		case *http2.DataFrame:
+			// Return the flow control credit so large responses and pushed streams keep flowing
+			if n := uint32(len(f.Data())); n > 0 {
+				if err := framer.WriteWindowUpdate(0, n); err != nil {
+					return pushes, err
+				}
+				if !f.StreamEnded() {
+					if err := framer.WriteWindowUpdate(f.StreamID, n); err != nil {
+						return pushes, err
+					}
+				}
+			}
+			if f.StreamID == streamID && f.StreamEnded() {
+				return pushes, nil
+			}
+		case *http2.HeadersFrame:
+			if f.StreamID == streamID && f.StreamEnded() {
+				return pushes, nil
+			}
+		case *http2.RSTStreamFrame:
+			if f.StreamID == streamID {
+				return pushes, errors.New("server reset the stream: " + f.ErrCode.String())
+			}
+		case *http2.GoAwayFrame:
+			return pushes, errors.New("server sent GOAWAY: " + f.ErrCode.String())
+		}
+	}
+}
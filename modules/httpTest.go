@@ -2,13 +2,25 @@
 package modules
 
 import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"time"
 
 	"github.com/ehsanghaffar/ultimate-internet-test/config"
 	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // TestHTTP performs an HTTP test on the given URL and returns the result.
@@ -30,66 +42,387 @@ import (
 //	    log.Println("Test failed:", result.Error)
 //	}
 func TestHTTP(url string, cfg *config.Config) *utils.HTTPTest {
+	return TestHTTPContext(context.Background(), url, cfg)
+}
+
+// TestHTTPContext is like TestHTTP but records a trace span as a child of ctx
+// and cancels the request when ctx is done.
+func TestHTTPContext(ctx context.Context, url string, cfg *config.Config) *utils.HTTPTest {
 	result := &utils.HTTPTest{
 		URL: url,
 	}
 
+	start := time.Now()
+	ctx, span := startSpan(ctx, "TestHTTP", attribute.String("url", url))
+	defer func() { endSpan(span, start, result.Error) }()
+
 	log.Println("URL:", url)
 
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		result.Error = err.Error()
+		result.ErrorCode = utils.ClassifyError(err)
 		log.Println("Error creating request:", url, err)
 		return result
 	}
+	setUserAgent(req, cfg)
+
+	// Asking for an encoding explicitly stops the transport from decoding the body transparently,
+	// so the compressed size on the wire can be measured
+	if cfg.MeasureCompression {
+		req.Header.Set("Accept-Encoding", "gzip, deflate")
+	}
+
+	// Record which server IP the connection was actually made to and the round-trip latency,
+	// and with cfg.EnableTrace every timing event in detail
+	var detail *utils.TraceDetail
+	if cfg.EnableTrace {
+		detail = &utils.TraceDetail{}
+		result.RequestTrace = detail
+	}
+
+	var getConn, dnsStart time.Time
+	trace := &httptrace.ClientTrace{
+		GetConn: func(hostPort string) {
+			getConn = time.Now()
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			dnsStart = time.Now()
+			if detail != nil {
+				detail.DNSStart = dnsStart
+			}
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			result.DNSResolutionTime = time.Since(dnsStart)
+			if detail != nil {
+				detail.DNSDone = time.Now()
+			}
+		},
+		GotFirstResponseByte: func() {
+			result.Latency = time.Since(getConn)
+			if detail != nil {
+				detail.GotFirstByte = time.Now()
+			}
+		},
+		ConnectStart: func(network, addr string) {
+			if detail != nil {
+				detail.ConnectStart = time.Now()
+			}
+		},
+		ConnectDone: func(network, addr string, err error) {
+			if detail != nil && err == nil {
+				detail.ConnectDone = time.Now()
+			}
+		},
+		TLSHandshakeStart: func() {
+			if detail != nil {
+				detail.TLSStart = time.Now()
+			}
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			if detail != nil {
+				detail.TLSDone = time.Now()
+			}
+		},
+		WroteHeaders: func() {
+			if detail != nil {
+				detail.WroteHeaders = time.Now()
+			}
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Conn == nil || info.Conn.RemoteAddr() == nil {
+				return
+			}
+			if host, _, err := net.SplitHostPort(info.Conn.RemoteAddr().String()); err == nil {
+				result.ServerIP = host
+			}
+			if info.Conn.LocalAddr() == nil {
+				return
+			}
+			if host, _, err := net.SplitHostPort(info.Conn.LocalAddr().String()); err == nil {
+				result.SourceIP = host
+			}
+		},
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+	transport, err := newHTTPRoundTripper(cfg)
+	if err != nil {
+		result.Error = err.Error()
+		result.ErrorCode = utils.ClassifyError(err)
+		log.Println("Error creating transport:", url, err)
+		return result
+	}
+
+	maxRedirects := cfg.MaxRedirects
+	if cfg.HTTPFollowRedirects && maxRedirects == 0 {
+		maxRedirects = config.DefaultFollowRedirectLimit
+	}
 
-	// Use provided timeout from config
+	// Use provided timeout from config; it bounds the whole redirect chain
+	var hopStart time.Time
 	client := http.Client{
-		Timeout: cfg.HTTPTimeout,
+		Transport: transport,
+		Timeout:   cfg.HTTPTimeout,
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			log.Println("Redirect:", req.URL)
-			return http.ErrUseLastResponse
+			// via holds the requests already made, so this is redirect number len(via).
+			// A refused redirect leaves the last hop to be timed once client.Do returns.
+			if len(via) > maxRedirects {
+				return http.ErrUseLastResponse
+			}
+			// The previous hop ended when its redirect response arrived
+			if cfg.HTTPFollowRedirects {
+				now := time.Now()
+				result.RedirectTimings = append(result.RedirectTimings, now.Sub(hopStart))
+				hopStart = now
+			}
+			return nil
 		},
 	}
 
+	result.AttemptCount = 1
+	hopStart = time.Now()
 	resp, err := client.Do(req)
 	if err != nil {
 		result.Error = err.Error()
+		result.ErrorCode = utils.ClassifyError(err)
 		log.Println("Error sending request:", url, err)
 		return result
 	}
 	defer resp.Body.Close()
 
+	// The final hop ended when its response arrived
+	if cfg.HTTPFollowRedirects {
+		result.RedirectTimings = append(result.RedirectTimings, time.Since(hopStart))
+	}
+
 	result.Status = resp.Status
 	result.Proto = resp.Proto
+	result.FinalURL = resp.Request.URL.String()
+	span.SetAttributes(attribute.Int("status_code", resp.StatusCode))
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		result.RateLimitDetected = true
+		log.Println("Rate limit detected:", url)
+	}
 
 	log.Println("Response status:", resp.Status, resp.Proto)
+	if result.ServerIP != "" {
+		log.Println("Server IP:", result.ServerIP, "source IP:", result.SourceIP)
+	}
 
 	if resp.TLS != nil {
 		result.TLSVersion = fmt.Sprintf("%d", resp.TLS.Version)
 		result.CipherSuite = fmt.Sprintf("%d", resp.TLS.CipherSuite)
+		result.CipherSuiteName = utils.ParseCipherSuiteID(resp.TLS.CipherSuite)
 		result.ServerName = resp.TLS.ServerName
+		if len(resp.TLS.PeerCertificates) > 0 {
+			result.CertExpiry = resp.TLS.PeerCertificates[0].NotAfter
+			result.TLSCertWarning = certWarning(result.CertExpiry, cfg.CertWarnDays)
+		}
 
 		log.Println("Response TLS version:", resp.TLS.Version)
-		log.Println("Response TLS cipher suite:", resp.TLS.CipherSuite)
+		log.Println("Response TLS cipher suite:", resp.TLS.CipherSuite, result.CipherSuiteName)
 		log.Println("Response TLS server name:", resp.TLS.ServerName)
+		if result.TLSCertWarning != "" {
+			log.Println("Certificate warning:", url, result.TLSCertWarning)
+		}
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	// Reading one byte past the limit tells a body of exactly the limit from a longer one
+	var reader io.Reader = resp.Body
+	if cfg.HTTPMaxBodySize > 0 {
+		reader = io.LimitReader(resp.Body, cfg.HTTPMaxBodySize+1)
+	}
+	body, err := io.ReadAll(reader)
 	if err != nil {
 		result.Error = err.Error()
+		result.ErrorCode = utils.ClassifyError(err)
 		log.Println("Error reading response:", url, err)
 		return result
 	}
+	if cfg.HTTPMaxBodySize > 0 && int64(len(body)) > cfg.HTTPMaxBodySize {
+		body = body[:cfg.HTTPMaxBodySize]
+		result.Truncated = true
+	}
+
+	if detail != nil {
+		detail.DNSTime = between(detail.DNSStart, detail.DNSDone)
+		detail.ConnectTime = between(detail.ConnectStart, detail.ConnectDone)
+		detail.TLSTime = between(detail.TLSStart, detail.TLSDone)
+		detail.ServerProcessTime = between(detail.WroteHeaders, detail.GotFirstByte)
+		detail.ContentTransferTime = between(detail.GotFirstByte, time.Now())
+	}
+
+	// ContentLength is -1 when the header is absent or the body is chunked
+	result.ContentLength = resp.ContentLength
+	result.BodyTruncated = result.ContentLength > 0 && int64(len(body)) < result.ContentLength
+
+	// The transport drops Content-Encoding from a body it decoded itself (resp.Uncompressed), so one
+	// left here means the body is still encoded. A truncated body cannot be decoded completely and
+	// an encoding without a decoder is kept as read.
+	if encoding := resp.Header.Get("Content-Encoding"); encoding != "" && !resp.Uncompressed && !result.Truncated {
+		decoded, err := decodeBody(encoding, body)
+		if err != nil {
+			log.Println("Error decoding response:", url, encoding, err)
+		} else {
+			result.CompressedSize = resp.ContentLength
+			if result.CompressedSize < 0 {
+				result.CompressedSize = int64(len(body))
+			}
+			result.UncompressedSize = int64(len(decoded))
+			result.CompressionSavings = result.UncompressedSize - result.CompressedSize
+			if result.CompressedSize > 0 {
+				result.CompressionRatio = float64(result.UncompressedSize) / float64(result.CompressedSize)
+			}
+			body = decoded
+
+			log.Println("Compression:", encoding, result.CompressedSize, "->", result.UncompressedSize,
+				"bytes, saved", result.CompressionSavings, fmt.Sprintf("(ratio %.2f)", result.CompressionRatio))
+		}
+	}
 
 	result.ResponseLength = len(body)
+	result.TotalTime = time.Since(start)
+
+	if cfg.ComputeBodyHash {
+		sum := sha256.Sum256(body)
+		result.ResponseBodyHash = hex.EncodeToString(sum[:])
+	}
+
+	if cfg.CaptureResponseHeaders {
+		result.ResponseHeaders = make(map[string]string, len(resp.Header))
+	}
 
 	for k, v := range resp.Header {
 		log.Println("Response header:", k, v)
+		if cfg.CaptureResponseHeaders && len(v) > 0 {
+			result.ResponseHeaders[k] = v[0]
+		}
+	}
+
+	// Multiple Server-Timing headers are equivalent to one comma separated header
+	if values := resp.Header.Values("Server-Timing"); len(values) > 0 {
+		result.ServerTiming = utils.ParseServerTiming(strings.Join(values, ","))
+	}
+	if result.ServerTiming != nil {
+		for _, m := range result.ServerTiming.Metrics {
+			log.Println("Server timing:", m.Name, m.Duration, m.Description)
+		}
+	}
+
+	if cfg.TestCaching {
+		checkCached(ctx, &client, resp, result, cfg)
+	}
+
+	if cfg.DetectHTTP2Push && resp.ProtoMajor == 2 {
+		result.AttemptCount++
+		pushes, err := countHTTP2Pushes(ctx, resp.Request.URL, cfg)
+		if err != nil {
+			log.Println("Error detecting HTTP/2 server push:", url, err)
+		}
+		result.HTTP2PushCount = pushes
+		log.Println("HTTP/2 server pushes:", pushes)
 	}
 
-	log.Println("Response length:", len(body))
+	log.Println("Response length:", len(body), "content length:", resp.ContentLength)
+	if result.BodyTruncated {
+		log.Println("Response body truncated:", url)
+	}
+	if result.Truncated {
+		log.Println("Stopped reading response body after", cfg.HTTPMaxBodySize, "bytes:", url)
+	}
+	for i, hop := range result.RedirectTimings {
+		log.Println("Redirect hop", i+1, "time:", hop)
+	}
+	log.Println("DNS resolution time:", result.DNSResolutionTime)
+	log.Println("Latency:", result.Latency)
+	if detail != nil {
+		log.Println("Trace: DNS", detail.DNSTime, "connect", detail.ConnectTime, "TLS", detail.TLSTime,
+			"server", detail.ServerProcessTime, "transfer", detail.ContentTransferTime)
+	}
+	log.Println("Total time:", result.TotalTime)
 	fmt.Println("------------------------------------------------------------")
 
 	return result
 }
+
+// checkCached repeats the request for the final URL of resp with If-None-Match and If-Modified-Since
+// taken from its ETag and Last-Modified headers, and sets result.Cached when the server answers
+// 304 Not Modified. Responses without validators cannot be revalidated and are left uncached.
+func checkCached(ctx context.Context, client *http.Client, resp *http.Response, result *utils.HTTPTest, cfg *config.Config) {
+	etag := resp.Header.Get("ETag")
+	lastModified := resp.Header.Get("Last-Modified")
+	if etag == "" && lastModified == "" {
+		log.Println("No ETag or Last-Modified, skipping cache check:", result.URL)
+		return
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, resp.Request.URL.String(), nil)
+	if err != nil {
+		log.Println("Error creating revalidation request:", result.URL, err)
+		return
+	}
+	setUserAgent(req, cfg)
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	result.AttemptCount++
+	revalidated, err := client.Do(req)
+	if err != nil {
+		log.Println("Error sending revalidation request:", result.URL, err)
+		return
+	}
+	defer revalidated.Body.Close()
+	io.Copy(io.Discard, revalidated.Body)
+
+	result.Cached = revalidated.StatusCode == http.StatusNotModified
+	log.Println("Revalidation status:", revalidated.Status, "cached:", result.Cached)
+}
+
+// decodeBody decodes a response body sent with the given Content-Encoding
+func decodeBody(encoding string, body []byte) ([]byte, error) {
+	var r io.ReadCloser
+	var err error
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "gzip", "x-gzip":
+		r, err = gzip.NewReader(bytes.NewReader(body))
+	case "deflate":
+		r, err = zlib.NewReader(bytes.NewReader(body))
+	case "identity":
+		return body, nil
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+// between returns the time from start to end, or 0 when either did not happen
+func between(start, end time.Time) time.Duration {
+	if start.IsZero() || end.IsZero() {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// certWarning returns a warning when expiry has passed or is less than warnDays away
+func certWarning(expiry time.Time, warnDays int) string {
+	daysLeft := time.Until(expiry).Hours() / 24
+	if daysLeft <= 0 {
+		return "Certificate EXPIRED"
+	}
+	if daysLeft < float64(warnDays) {
+		return fmt.Sprintf("Certificate expires in %d days", int(daysLeft))
+	}
+	return ""
+}
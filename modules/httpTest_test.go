@@ -0,0 +1,449 @@
+package modules
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go/http3"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+)
+
+func TestTestHTTP(t *testing.T) {
+	server := utils.NewMockHTTPServer([]utils.MockResponse{
+		{Path: "/ok", Status: http.StatusOK, Body: "hello"},
+		{Path: "/limited", Status: http.StatusTooManyRequests},
+		{Path: "/redirect", Status: http.StatusFound, Headers: map[string]string{"Location": "/ok"}},
+		{Path: "/slow", Status: http.StatusOK, Delay: 500 * time.Millisecond},
+	})
+	defer server.Close()
+
+	tests := []struct {
+		name         string
+		path         string
+		maxRedirects int
+		timeout      time.Duration
+		wantStatus   string
+		wantLength   int
+		wantFinal    string
+		wantLimited  bool
+		wantError    bool
+	}{
+		{name: "ok", path: "/ok", wantStatus: "200 OK", wantLength: 5, wantFinal: "/ok"},
+		{name: "not found", path: "/missing", wantStatus: "404 Not Found", wantLength: 19, wantFinal: "/missing"},
+		{name: "rate limited", path: "/limited", wantStatus: "429 Too Many Requests", wantFinal: "/limited", wantLimited: true},
+		{name: "redirect not followed", path: "/redirect", wantStatus: "302 Found", wantFinal: "/redirect"},
+		{name: "redirect followed", path: "/redirect", maxRedirects: 1, wantStatus: "200 OK", wantLength: 5, wantFinal: "/ok"},
+		{name: "timeout", path: "/slow", timeout: 50 * time.Millisecond, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.New()
+			cfg.MaxRedirects = tt.maxRedirects
+			if tt.timeout > 0 {
+				cfg.HTTPTimeout = tt.timeout
+			}
+
+			result := TestHTTP(server.URL+tt.path, cfg)
+
+			if tt.wantError {
+				if result.Error == "" {
+					t.Fatal("expected an error")
+				}
+				if result.ErrorCode == utils.ErrCodeNone {
+					t.Error("expected a non-zero error code")
+				}
+				return
+			}
+
+			if result.Error != "" {
+				t.Fatalf("unexpected error: %s", result.Error)
+			}
+			if result.Status != tt.wantStatus {
+				t.Errorf("Status = %q, want %q", result.Status, tt.wantStatus)
+			}
+			if result.ResponseLength != tt.wantLength {
+				t.Errorf("ResponseLength = %d, want %d", result.ResponseLength, tt.wantLength)
+			}
+			if !strings.HasSuffix(result.FinalURL, tt.wantFinal) {
+				t.Errorf("FinalURL = %q, want suffix %q", result.FinalURL, tt.wantFinal)
+			}
+			if result.RateLimitDetected != tt.wantLimited {
+				t.Errorf("RateLimitDetected = %t, want %t", result.RateLimitDetected, tt.wantLimited)
+			}
+			if result.ServerIP != "127.0.0.1" {
+				t.Errorf("ServerIP = %q, want 127.0.0.1", result.ServerIP)
+			}
+		})
+	}
+}
+
+func TestTestHTTPCapturesHeaders(t *testing.T) {
+	server := utils.NewMockHTTPServer([]utils.MockResponse{
+		{Path: "/", Headers: map[string]string{"X-Test": "yes"}, Body: "hello"},
+	})
+	defer server.Close()
+
+	cfg := config.New()
+	cfg.CaptureResponseHeaders = true
+
+	result := TestHTTP(server.URL+"/", cfg)
+	if result.Error != "" {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+	if got := result.ResponseHeaders["X-Test"]; got != "yes" {
+		t.Errorf("ResponseHeaders[X-Test] = %q, want %q", got, "yes")
+	}
+	if result.ContentLength != 5 || result.BodyTruncated {
+		t.Errorf("ContentLength = %d, BodyTruncated = %t, want 5, false", result.ContentLength, result.BodyTruncated)
+	}
+}
+
+func TestTestHTTPServerTiming(t *testing.T) {
+	server := utils.NewMockHTTPServer([]utils.MockResponse{
+		{Path: "/", Headers: map[string]string{"Server-Timing": `cache;desc="Cache, Read";dur=23.2, db;dur=53, app`}},
+	})
+	defer server.Close()
+
+	result := TestHTTP(server.URL+"/", config.New())
+	if result.Error != "" {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+	if result.ServerTiming == nil {
+		t.Fatal("ServerTiming is nil")
+	}
+
+	want := []utils.ServerTimingMetric{
+		{Name: "cache", Description: "Cache, Read", Duration: 23200 * time.Microsecond},
+		{Name: "db", Duration: 53 * time.Millisecond},
+		{Name: "app"},
+	}
+	if len(result.ServerTiming.Metrics) != len(want) {
+		t.Fatalf("got %d metrics, want %d: %+v", len(result.ServerTiming.Metrics), len(want), result.ServerTiming.Metrics)
+	}
+	for i, m := range result.ServerTiming.Metrics {
+		if m != want[i] {
+			t.Errorf("metric %d = %+v, want %+v", i, m, want[i])
+		}
+	}
+}
+
+func TestTestHTTPCaching(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+
+	cfg := config.New()
+	cfg.TestCaching = true
+
+	result := TestHTTP(server.URL, cfg)
+	if result.Error != "" {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+	if !result.Cached {
+		t.Error("Cached = false, want true")
+	}
+	if result.AttemptCount != 2 {
+		t.Errorf("AttemptCount = %d, want 2", result.AttemptCount)
+	}
+	if result.Status != "200 OK" {
+		t.Errorf("Status = %q, want the first response's status", result.Status)
+	}
+}
+
+func TestTestHTTPCompression(t *testing.T) {
+	body := strings.Repeat("hello ", 1000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write([]byte(body))
+		zw.Close()
+
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(buf.Bytes())
+	}))
+	defer server.Close()
+
+	// By default the transport asks for gzip and decodes the body transparently
+	cfg := config.New()
+	result := TestHTTP(server.URL, cfg)
+	if result.Error != "" {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+	if result.ResponseLength != len(body) || result.CompressedSize != 0 {
+		t.Errorf("ResponseLength = %d, CompressedSize = %d, want %d and 0", result.ResponseLength, result.CompressedSize, len(body))
+	}
+
+	cfg.MeasureCompression = true
+	result = TestHTTP(server.URL, cfg)
+	if result.Error != "" {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+	if result.UncompressedSize != int64(len(body)) || result.ResponseLength != len(body) {
+		t.Errorf("UncompressedSize = %d, ResponseLength = %d, want %d", result.UncompressedSize, result.ResponseLength, len(body))
+	}
+	if result.CompressedSize == 0 || result.CompressedSize >= result.UncompressedSize {
+		t.Errorf("CompressedSize = %d, want between 0 and %d", result.CompressedSize, len(body))
+	}
+	if result.CompressionSavings != result.UncompressedSize-result.CompressedSize {
+		t.Errorf("CompressionSavings = %d, want %d", result.CompressionSavings, result.UncompressedSize-result.CompressedSize)
+	}
+	if result.CompressionRatio <= 1 {
+		t.Errorf("CompressionRatio = %f, want > 1", result.CompressionRatio)
+	}
+}
+
+func TestTestHTTPVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	cfg := config.New()
+	cfg.HTTPVersion = utils.HTTPVersion11
+	result := TestHTTP(server.URL, cfg)
+	if result.Error != "" {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+	if result.Proto != "HTTP/1.1" {
+		t.Errorf("Proto = %q, want HTTP/1.1", result.Proto)
+	}
+
+	// HTTP/3 needs an https URL and a server speaking QUIC
+	cfg.HTTPVersion = utils.HTTPVersion3
+	if result := TestHTTP(server.URL, cfg); result.Error == "" {
+		t.Error("expected an error for HTTP/3 against an HTTP/1.1 server")
+	}
+
+	if err := utils.ValidateHTTPVersion("1.0"); err == nil {
+		t.Error("expected HTTP version 1.0 to be rejected")
+	}
+}
+
+func TestHTTP3RoundTripper(t *testing.T) {
+	// The TLS server only provides a certificate for the HTTP/3 server
+	tlsServer := httptest.NewTLSServer(http.NotFoundHandler())
+	defer tlsServer.Close()
+
+	packetConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer packetConn.Close()
+
+	server := &http3.Server{
+		TLSConfig: http3.ConfigureTLSConfig(&tls.Config{Certificates: tlsServer.TLS.Certificates}),
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("hello"))
+		}),
+	}
+	go server.Serve(packetConn)
+	defer server.Close()
+
+	cfg := config.New()
+	transport, err := newHTTP3RoundTripper(cfg, &tls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := (&http.Client{Transport: transport, Timeout: 5 * time.Second}).Get("https://" + packetConn.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.Proto != "HTTP/3.0" {
+		t.Errorf("Proto = %q, want HTTP/3.0", resp.Proto)
+	}
+	if body, _ := io.ReadAll(resp.Body); string(body) != "hello" {
+		t.Errorf("body = %q, want %q", body, "hello")
+	}
+}
+
+func TestTestHTTPBodyHash(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+
+	cfg := config.New()
+	if result := TestHTTP(server.URL, cfg); result.ResponseBodyHash != "" {
+		t.Errorf("ResponseBodyHash = %q without ComputeBodyHash, want empty", result.ResponseBodyHash)
+	}
+
+	cfg.ComputeBodyHash = true
+	result := TestHTTP(server.URL, cfg)
+	if want := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"; result.ResponseBodyHash != want {
+		t.Errorf("ResponseBodyHash = %q, want %q", result.ResponseBodyHash, want)
+	}
+}
+
+func TestTestHTTPFollowRedirects(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/a", func(w http.ResponseWriter, r *http.Request) { http.Redirect(w, r, "/b", http.StatusFound) })
+	mux.HandleFunc("/b", func(w http.ResponseWriter, r *http.Request) { http.Redirect(w, r, "/c", http.StatusFound) })
+	mux.HandleFunc("/c", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("done")) })
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cfg := config.New()
+	cfg.HTTPFollowRedirects = true
+	result := TestHTTP(server.URL+"/a", cfg)
+	if result.FinalURL != server.URL+"/c" {
+		t.Errorf("FinalURL = %q, want %q", result.FinalURL, server.URL+"/c")
+	}
+	if len(result.RedirectTimings) != 3 {
+		t.Fatalf("RedirectTimings = %v, want 3 hops", result.RedirectTimings)
+	}
+	for i, hop := range result.RedirectTimings {
+		if hop <= 0 {
+			t.Errorf("RedirectTimings[%d] = %v, want > 0", i, hop)
+		}
+	}
+
+	cfg.MaxRedirects = 1
+	result = TestHTTP(server.URL+"/a", cfg)
+	if result.FinalURL != server.URL+"/b" {
+		t.Errorf("FinalURL with MaxRedirects 1 = %q, want %q", result.FinalURL, server.URL+"/b")
+	}
+	if len(result.RedirectTimings) != 2 {
+		t.Errorf("RedirectTimings with MaxRedirects 1 = %v, want 2 hops", result.RedirectTimings)
+	}
+}
+
+func TestTestHTTPMaxBodySize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello world"))
+	}))
+	defer server.Close()
+
+	cfg := config.New()
+	cfg.HTTPMaxBodySize = 5
+	cfg.ComputeBodyHash = true
+	result := TestHTTP(server.URL, cfg)
+	if result.Error != "" {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+	// The cap cut the body short of its Content-Length, so both flags are set
+	if !result.Truncated || !result.BodyTruncated || result.ResponseLength != 5 {
+		t.Errorf("Truncated = %t, BodyTruncated = %t, ResponseLength = %d, want true, true, 5",
+			result.Truncated, result.BodyTruncated, result.ResponseLength)
+	}
+	// SHA-256 of "hello", the bytes actually read
+	if want := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"; result.ResponseBodyHash != want {
+		t.Errorf("ResponseBodyHash = %q, want %q", result.ResponseBodyHash, want)
+	}
+
+	cfg.HTTPMaxBodySize = 11
+	if result := TestHTTP(server.URL, cfg); result.Truncated || result.ResponseLength != 11 {
+		t.Errorf("Truncated = %t, ResponseLength = %d at the exact limit, want false, 11", result.Truncated, result.ResponseLength)
+	}
+}
+
+func TestTestHTTPRequestTrace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+
+	cfg := config.New()
+	if result := TestHTTP(server.URL, cfg); result.RequestTrace != nil {
+		t.Errorf("RequestTrace = %+v without EnableTrace, want nil", result.RequestTrace)
+	}
+
+	cfg.EnableTrace = true
+	result := TestHTTP(server.URL, cfg)
+	if result.Error != "" {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+	trace := result.RequestTrace
+	if trace == nil {
+		t.Fatal("RequestTrace = nil with EnableTrace")
+	}
+	if trace.ConnectTime <= 0 || trace.TLSTime != 0 {
+		t.Errorf("ConnectTime = %v, TLSTime = %v, want > 0 and 0 for plain HTTP", trace.ConnectTime, trace.TLSTime)
+	}
+	if trace.ServerProcessTime < 10*time.Millisecond {
+		t.Errorf("ServerProcessTime = %v, want at least the 10ms handler delay", trace.ServerProcessTime)
+	}
+	if trace.WroteHeaders.IsZero() || trace.GotFirstByte.Before(trace.WroteHeaders) {
+		t.Errorf("WroteHeaders = %v, GotFirstByte = %v, want headers written before the first byte", trace.WroteHeaders, trace.GotFirstByte)
+	}
+}
+
+func TestRepeatWaitsBeforeEveryRun(t *testing.T) {
+	waits, runs := 0, 0
+	wait := func(context.Context) error {
+		waits++
+		return nil
+	}
+	repeat(context.Background(), 3, 0, wait, func() {
+		if waits != runs+1 {
+			t.Errorf("run %d started after %d waits, want %d", runs+1, waits, runs+1)
+		}
+		runs++
+	})
+	if waits != 3 || runs != 3 {
+		t.Errorf("waits = %d, runs = %d, want 3, 3", waits, runs)
+	}
+}
+
+func TestRepeatHTTPTestLimitedStopsOnCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	// The limiter starts with one token and refills far too slowly for a second run
+	limiter := utils.NewRateLimiter(0.001)
+	defer limiter.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	runs := RepeatHTTPTestLimited(ctx, server.URL, 3, 0, limiter, config.New())
+	if len(runs) != 1 {
+		t.Errorf("runs = %d, want 1", len(runs))
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("returned after %s, want soon after the context is done", elapsed)
+	}
+}
+
+func TestCertWarning(t *testing.T) {
+	tests := []struct {
+		name   string
+		expiry time.Time
+		want   string
+	}{
+		{name: "expired", expiry: time.Now().Add(-time.Hour), want: "Certificate EXPIRED"},
+		{name: "near expiry", expiry: time.Now().Add(10*24*time.Hour + time.Hour), want: "Certificate expires in 10 days"},
+		{name: "valid", expiry: time.Now().Add(90 * 24 * time.Hour), want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := certWarning(tt.expiry, 30); got != tt.want {
+				t.Errorf("certWarning() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
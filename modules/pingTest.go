@@ -1,14 +1,18 @@
 package modules
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"net"
 	"os"
 	"os/signal"
+	"time"
 
 	"github.com/ehsanghaffar/ultimate-internet-test/config"
 	"github.com/ehsanghaffar/ultimate-internet-test/utils"
 	"github.com/go-ping/ping"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // PingCheck performs a ping test on the given domain and returns the result.
@@ -31,13 +35,35 @@ import (
 //	        result.Transmitted, result.Received, result.Loss)
 //	}
 func PingCheck(domain string, cfg *config.Config) *utils.PingTest {
+	return PingCheckContext(context.Background(), domain, cfg)
+}
+
+// PingCheckContext is like PingCheck but records a trace span as a child of ctx
+// and stops pinging when ctx is done.
+func PingCheckContext(ctx context.Context, domain string, cfg *config.Config) *utils.PingTest {
 	result := &utils.PingTest{
 		URL: domain,
 	}
 
-	pinger, err := ping.NewPinger(domain)
+	start := time.Now()
+	ctx, span := startSpan(ctx, "PingCheck", attribute.String("url", domain))
+	defer func() { endSpan(span, start, result.Error) }()
+
+	warnBypassesProxy("Ping test", cfg)
+
+	target, err := resolvePingTarget(ctx, domain, cfg)
+	if err != nil {
+		result.Error = err.Error()
+		result.ErrorCode = utils.ClassifyError(err)
+		log.Printf("Failed to resolve %s: %v\n", domain, err)
+		fmt.Println("------------------------------------------------------------")
+		return result
+	}
+
+	pinger, err := ping.NewPinger(target)
 	if err != nil {
 		result.Error = err.Error()
+		result.ErrorCode = utils.ClassifyError(err)
 		log.Printf("Failed to create pinger for %s: %v\n", domain, err)
 		fmt.Println("------------------------------------------------------------")
 		return result
@@ -46,6 +72,17 @@ func PingCheck(domain string, cfg *config.Config) *utils.PingTest {
 	// Set ping count from config
 	pinger.Count = cfg.PingCount
 
+	if cfg.PingSourceAddr != "" {
+		if err := utils.ValidateLocalAddr(cfg.PingSourceAddr); err != nil {
+			result.Error = err.Error()
+			result.ErrorCode = utils.ClassifyError(err)
+			log.Printf("Invalid ping source address for %s: %v\n", domain, err)
+			fmt.Println("------------------------------------------------------------")
+			return result
+		}
+		pinger.Source = cfg.PingSourceAddr
+	}
+
 	// Listen for Ctrl-C
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt)
@@ -55,9 +92,23 @@ func PingCheck(domain string, cfg *config.Config) *utils.PingTest {
 		}
 	}()
 
+	// Stop pinging when the context is cancelled
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			pinger.Stop()
+		case <-done:
+		}
+	}()
+
 	pinger.OnRecv = func(pkt *ping.Packet) {
-		log.Printf("%d bytes from %s: icmp_seq=%d time=%v\n",
-			pkt.Nbytes, pkt.IPAddr, pkt.Seq, pkt.Rtt)
+		log.Printf("%d bytes from %s: icmp_seq=%d time=%v ttl=%v\n",
+			pkt.Nbytes, pkt.IPAddr, pkt.Seq, pkt.Rtt, pkt.Ttl)
+
+		// Keep the TTL of the last reply seen
+		result.TTL = pkt.Ttl
 	}
 
 	pinger.OnDuplicateRecv = func(pkt *ping.Packet) {
@@ -76,11 +127,36 @@ func PingCheck(domain string, cfg *config.Config) *utils.PingTest {
 		result.Transmitted = stats.PacketsSent
 		result.Received = stats.PacketsRecv
 		result.Loss = stats.PacketLoss
+		result.MinRTTMS = durationMS(stats.MinRtt)
+		result.AvgRTTMS = durationMS(stats.AvgRtt)
+		result.MaxRTTMS = durationMS(stats.MaxRtt)
+		result.StdDevRTTMS = durationMS(stats.StdDevRtt)
+		result.PerceivedQuality = utils.RatePingQuality(stats.AvgRtt, stats.PacketLoss)
+
+		result.Statistics = &utils.PingStatistics{
+			Duplicate: stats.PacketsRecvDuplicates,
+		}
+		if stats.PacketsSent > 0 {
+			result.Statistics.DuplicateLoss = float64(stats.PacketsRecvDuplicates) / float64(stats.PacketsSent) * 100
+		}
+		if stats.IPAddr != nil {
+			result.Statistics.IPAddr = stats.IPAddr.String()
+		}
+		for _, rtt := range stats.Rtts {
+			result.Statistics.RTTsMS = append(result.Statistics.RTTsMS, durationMS(rtt))
+		}
+
+		span.SetAttributes(
+			attribute.Int("packets_sent", stats.PacketsSent),
+			attribute.Int("packets_received", stats.PacketsRecv),
+			attribute.Float64("packet_loss", stats.PacketLoss),
+		)
 	}
 
 	fmt.Printf("PING %s (%s):\n", pinger.Addr(), pinger.IPAddr())
 	if err := pinger.Run(); err != nil {
 		result.Error = err.Error()
+		result.ErrorCode = utils.ClassifyError(err)
 		log.Printf("Ping check failed for %s: %v\n", domain, err)
 		fmt.Println("------------------------------------------------------------")
 		return result
@@ -89,3 +165,34 @@ func PingCheck(domain string, cfg *config.Config) *utils.PingTest {
 	fmt.Println("------------------------------------------------------------")
 	return result
 }
+
+// durationMS converts a duration to fractional milliseconds
+func durationMS(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// resolvePingTarget resolves domain within cfg.DNSTimeout, preferring IPv4 like the pinger itself.
+// IP addresses are returned unchanged.
+func resolvePingTarget(ctx context.Context, domain string, cfg *config.Config) (string, error) {
+	if net.ParseIP(domain) != nil {
+		return domain, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.DNSTimeout)
+	defer cancel()
+
+	ips, err := connectionResolver(cfg).LookupIP(ctx, "ip", domain)
+	if err != nil {
+		return "", err
+	}
+	if len(ips) == 0 {
+		return "", utils.NewNetworkError("Ping", utils.ErrCodeDNS, "no addresses found for "+domain, nil)
+	}
+
+	for _, ip := range ips {
+		if ip.To4() != nil {
+			return ip.String(), nil
+		}
+	}
+	return ips[0].String(), nil
+}
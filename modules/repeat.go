@@ -0,0 +1,105 @@
+package modules
+
+import (
+	"context"
+	"time"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+)
+
+// RunHTTPTestRepeatedly runs TestHTTP n times sequentially, pausing delay between runs,
+// and returns the minimum, maximum and average response times and the number of failed runs.
+//
+// Parameters:
+//   - url: The URL to test
+//   - n: Number of runs (values below 1 run once)
+//   - delay: Pause between consecutive runs
+//   - cfg: Configuration passed to every run
+//
+// Returns:
+//   - *HTTPTestStats: Pointer to HTTPTestStats struct summarizing the runs
+//
+// Example:
+//
+//	cfg := config.New()
+//	stats := RunHTTPTestRepeatedly("https://example.com", 5, time.Second, cfg)
+//	log.Println("Average response time:", stats.AvgResponseTime)
+func RunHTTPTestRepeatedly(url string, n int, delay time.Duration, cfg *config.Config) *utils.HTTPTestStats {
+	return utils.NewHTTPTestStats(url, RepeatHTTPTestContext(context.Background(), url, n, delay, cfg))
+}
+
+// RunSpeedTestRepeatedly runs CheckSpeed n times sequentially, pausing delay between runs,
+// and returns the minimum, maximum and average download speeds and the number of failed runs.
+//
+// Parameters:
+//   - url: The URL to download
+//   - n: Number of runs (values below 1 run once)
+//   - delay: Pause between consecutive runs
+//   - cfg: Configuration passed to every run
+//
+// Returns:
+//   - *SpeedTestStats: Pointer to SpeedTestStats struct summarizing the runs
+//
+// Example:
+//
+//	cfg := config.New()
+//	stats := RunSpeedTestRepeatedly("https://example.com/file", 3, 0, cfg)
+//	log.Printf("Average speed: %.2f Mbps\n", stats.AvgMbps)
+func RunSpeedTestRepeatedly(url string, n int, delay time.Duration, cfg *config.Config) *utils.SpeedTestStats {
+	return utils.NewSpeedTestStats(url, RepeatSpeedTestContext(context.Background(), url, n, delay, cfg))
+}
+
+// RepeatHTTPTestContext runs TestHTTPContext n times and returns every result.
+// It stops early when ctx is done.
+func RepeatHTTPTestContext(ctx context.Context, url string, n int, delay time.Duration, cfg *config.Config) []utils.HTTPTest {
+	return RepeatHTTPTestLimited(ctx, url, n, delay, nil, cfg)
+}
+
+// RepeatHTTPTestLimited is like RepeatHTTPTestContext but takes a token from limiter before every run,
+// so repeats count against the request rate too. A nil limiter does not limit the runs.
+func RepeatHTTPTestLimited(ctx context.Context, url string, n int, delay time.Duration, limiter *utils.RateLimiter, cfg *config.Config) []utils.HTTPTest {
+	var runs []utils.HTTPTest
+	repeat(ctx, n, delay, limiter.Wait, func() {
+		runs = append(runs, *TestHTTPContext(ctx, url, cfg))
+	})
+	return runs
+}
+
+// RepeatSpeedTestContext runs CheckSpeedContext n times and returns every result.
+// It stops early when ctx is done.
+func RepeatSpeedTestContext(ctx context.Context, url string, n int, delay time.Duration, cfg *config.Config) []utils.SpeedTest {
+	var runs []utils.SpeedTest
+	repeat(ctx, n, delay, nil, func() {
+		runs = append(runs, *CheckSpeedContext(ctx, url, cfg))
+	})
+	return runs
+}
+
+// repeat calls run n times (at least once), sleeping delay in between.
+// When wait is not nil it is called before every run, e.g. to take a rate limiter token,
+// and an error from it stops the runs.
+func repeat(ctx context.Context, n int, delay time.Duration, wait func(context.Context) error, run func()) {
+	if n < 1 {
+		n = 1
+	}
+
+	for i := 0; i < n; i++ {
+		if i > 0 && delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+		if wait != nil && wait(ctx) != nil {
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		run()
+	}
+}
@@ -0,0 +1,80 @@
+package modules
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+)
+
+var (
+	// dnsCaches holds the cache shared by every connection the tests make once DNS caching
+	// is enabled, one per TTL so configs with different TTLs never share entries
+	dnsCaches   = make(map[time.Duration]*utils.DNSCache)
+	dnsCachesMu sync.Mutex
+)
+
+// connectionResolver returns the resolver connections should use: the configured resolver,
+// wrapped in the shared in-memory cache when cfg.DNSCacheEnabled is set.
+// Tests that measure DNS itself use newResolver directly and are never cached.
+func connectionResolver(cfg *config.Config) *net.Resolver {
+	upstream := newResolver(cfg.DNSResolver, cfg.DNSTimeout)
+	if !cfg.DNSCacheEnabled {
+		return upstream
+	}
+
+	return utils.NewCachedResolver(sharedDNSCache(cfg.DNSCacheTTL), upstream)
+}
+
+// sharedDNSCache returns the connection DNS cache for ttl, creating it on first use
+func sharedDNSCache(ttl time.Duration) *utils.DNSCache {
+	dnsCachesMu.Lock()
+	defer dnsCachesMu.Unlock()
+
+	cache, ok := dnsCaches[ttl]
+	if !ok {
+		cache = utils.NewDNSCache(ttl)
+		dnsCaches[ttl] = cache
+	}
+	return cache
+}
+
+// newResolver returns a resolver that sends queries to the given "host:port" address.
+// An empty address queries the system's configured name servers. Either way connections
+// to the name server are dialed with timeout.
+func newResolver(address string, timeout time.Duration) *net.Resolver {
+	dialer := &net.Dialer{Timeout: timeout}
+	if address == "" {
+		return &net.Resolver{
+			PreferGo: true,
+			Dial:     dialer.DialContext,
+		}
+	}
+
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, address)
+		},
+	}
+}
+
+// lookupIPv4 resolves the A records of domain using the given resolver
+func lookupIPv4(resolver *net.Resolver, domain string, timeout time.Duration) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ips, err := resolver.LookupIP(ctx, "ip4", domain)
+	if err != nil {
+		return nil, err
+	}
+
+	addrs := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		addrs = append(addrs, ip.String())
+	}
+	return addrs, nil
+}
@@ -1,18 +1,25 @@
 package modules
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/http/httptrace"
 	"time"
 
 	"github.com/ehsanghaffar/ultimate-internet-test/config"
 	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // CheckSpeed performs a speed test by downloading from the given URL and returns the speed result.
 // It uses timeout configuration from the config parameter. The function measures download speed in Mbps.
+// Only the body transfer is timed; connection setup before the first response byte is reported separately.
+// Bytes arriving during the first cfg.SpeedTestWarmup of the body are read but left out of the speed,
+// so TCP slow start does not drag the result down. Downloads that finish within the warmup are
+// measured as a whole.
 //
 // Parameters:
 //   - url: The URL to download from for speed testing
@@ -29,43 +36,125 @@ import (
 //	    log.Printf("Download speed: %.2f Mbps\n", result.DownloadMbps)
 //	}
 func CheckSpeed(url string, cfg *config.Config) *utils.SpeedTest {
+	return CheckSpeedContext(context.Background(), url, cfg)
+}
+
+// CheckSpeedContext is like CheckSpeed but records a trace span as a child of ctx
+// and cancels the download when ctx is done.
+func CheckSpeedContext(ctx context.Context, url string, cfg *config.Config) *utils.SpeedTest {
 	result := &utils.SpeedTest{
 		URL: url,
 	}
 
 	startTime := time.Now()
+	ctx, span := startSpan(ctx, "CheckSpeed", attribute.String("url", url))
+	defer func() { endSpan(span, startTime, result.Error) }()
+
+	transport, err := newHTTPRoundTripper(cfg)
+	if err != nil {
+		result.Error = err.Error()
+		result.ErrorCode = utils.ClassifyError(err)
+		log.Println(err)
+		return result
+	}
 
 	// Create a client with timeout from config
 	client := &http.Client{
-		Timeout: cfg.SpeedTestTimeout,
+		Transport: transport,
+		Timeout:   cfg.SpeedTestTimeout,
 	}
 
-	resp, err := client.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		result.Error = err.Error()
+		result.ErrorCode = utils.ClassifyError(err)
 		log.Println(err)
 		return result
 	}
-	defer resp.Body.Close()
+	setUserAgent(req, cfg)
+
+	// DNS, connect, TLS and waiting for the server all happen before the first response byte
+	var firstByte time.Time
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+		GotFirstResponseByte: func() {
+			firstByte = time.Now()
+		},
+	}))
 
-	body, err := io.ReadAll(resp.Body)
+	resp, err := client.Do(req)
 	if err != nil {
 		result.Error = err.Error()
+		result.ErrorCode = utils.ClassifyError(err)
 		log.Println(err)
 		return result
 	}
+	defer resp.Body.Close()
+	result.Protocol = resp.Proto
+
+	span.SetAttributes(attribute.Int("status_code", resp.StatusCode))
+
+	if firstByte.IsZero() {
+		firstByte = time.Now()
+	}
+	result.ConnectionSetupTime = firstByte.Sub(startTime)
+
+	// The byte counter starts when the last chunk of the warmup arrived
+	buf := make([]byte, 32*1024)
+	bodyStart := firstByte
+	warmupEnd := bodyStart.Add(cfg.SpeedTestWarmup)
+	measureStart := bodyStart
+	var total, measured int64
+	for {
+		n, err := resp.Body.Read(buf)
+		if n > 0 {
+			total += int64(n)
+			if now := time.Now(); now.Before(warmupEnd) {
+				result.WarmupBytesDiscarded += int64(n)
+				measureStart = now
+			} else {
+				measured += int64(n)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			result.Error = err.Error()
+			result.ErrorCode = utils.ClassifyError(err)
+			log.Println(err)
+			return result
+		}
+	}
 
 	elapsedTime := time.Since(startTime)
 	result.ElapsedTime = elapsedTime
-	result.BytesReceived = len(body)
+	result.ElapsedTimeMS = elapsedTime.Milliseconds()
+	result.BytesReceived = int(total)
+	result.DownloadDuration = time.Since(bodyStart)
+
+	measureTime := time.Since(measureStart)
+	if measured == 0 {
+		// Nothing arrived after the warmup, so the whole transfer is measured
+		result.WarmupBytesDiscarded = 0
+		measured = total
+		measureTime = result.DownloadDuration
+	}
+	if measureTime <= 0 {
+		measureTime = elapsedTime
+	}
 
 	// Calculate speed in Mbps
-	speed := float64(len(body)) / elapsedTime.Seconds()
+	speed := float64(measured) / measureTime.Seconds()
 	result.DownloadMbps = (speed / float64(config.BytesToMegabytes)) * float64(config.BytesToBits)
+	result.PerceivedQuality = utils.RateDownloadQuality(result.DownloadMbps)
+	span.SetAttributes(attribute.Float64("download_mbps", result.DownloadMbps))
 
 	log.Println("URL:", url)
-	log.Printf("Download speed: %.2f Mbps\n", result.DownloadMbps)
-	log.Printf("Elapsed time: %s\n", elapsedTime)
+	log.Printf("Download speed: %.2f Mbps (%s) over %s\n", result.DownloadMbps, result.PerceivedQuality, result.Protocol)
+	log.Printf("Elapsed time: %s (setup %s, download %s)\n", elapsedTime, result.ConnectionSetupTime, result.DownloadDuration)
+	if result.WarmupBytesDiscarded > 0 {
+		log.Printf("Warmup bytes discarded: %d\n", result.WarmupBytesDiscarded)
+	}
 	fmt.Println("------------------------------------------------------------")
 
 	return result
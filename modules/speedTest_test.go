@@ -0,0 +1,89 @@
+package modules
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+)
+
+func TestCheckSpeed(t *testing.T) {
+	body := strings.Repeat("x", 1<<20)
+	server := utils.NewMockHTTPServer([]utils.MockResponse{
+		{Path: "/download", Status: http.StatusOK, Body: body, Delay: 20 * time.Millisecond},
+	})
+	defer server.Close()
+
+	result := CheckSpeed(server.URL+"/download", config.New())
+	if result.Error != "" {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+	if result.BytesReceived != len(body) {
+		t.Errorf("BytesReceived = %d, want %d", result.BytesReceived, len(body))
+	}
+	if result.DownloadMbps <= 0 {
+		t.Errorf("DownloadMbps = %f, want > 0", result.DownloadMbps)
+	}
+	if want := utils.RateDownloadQuality(result.DownloadMbps); result.PerceivedQuality != want {
+		t.Errorf("PerceivedQuality = %q, want %q", result.PerceivedQuality, want)
+	}
+	if result.ElapsedTime < 20*time.Millisecond {
+		t.Errorf("ElapsedTime = %s, want at least the 20ms server delay", result.ElapsedTime)
+	}
+	if result.ElapsedTimeMS != result.ElapsedTime.Milliseconds() {
+		t.Errorf("ElapsedTimeMS = %d, want %d", result.ElapsedTimeMS, result.ElapsedTime.Milliseconds())
+	}
+}
+
+func TestCheckSpeedWarmup(t *testing.T) {
+	chunk := strings.Repeat("x", 64*1024)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(chunk))
+		w.(http.Flusher).Flush()
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte(chunk))
+	}))
+	defer server.Close()
+
+	cfg := config.New()
+	cfg.SpeedTestWarmup = 50 * time.Millisecond
+
+	result := CheckSpeed(server.URL, cfg)
+	if result.Error != "" {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+	if result.BytesReceived != 2*len(chunk) {
+		t.Errorf("BytesReceived = %d, want %d", result.BytesReceived, 2*len(chunk))
+	}
+	if result.WarmupBytesDiscarded != int64(len(chunk)) {
+		t.Errorf("WarmupBytesDiscarded = %d, want %d", result.WarmupBytesDiscarded, len(chunk))
+	}
+	if result.DownloadMbps <= 0 {
+		t.Errorf("DownloadMbps = %f, want > 0", result.DownloadMbps)
+	}
+}
+
+func TestCheckSpeedTimeout(t *testing.T) {
+	server := utils.NewMockHTTPServer([]utils.MockResponse{
+		{Path: "/slow", Status: http.StatusOK, Body: "x", Delay: 500 * time.Millisecond},
+	})
+	defer server.Close()
+
+	cfg := config.New()
+	cfg.SpeedTestTimeout = 50 * time.Millisecond
+
+	result := CheckSpeed(server.URL+"/slow", cfg)
+	if result.Error == "" {
+		t.Fatal("expected a timeout error")
+	}
+	if result.ErrorCode != utils.ErrCodeTimeout {
+		t.Errorf("ErrorCode = %d, want %d", result.ErrorCode, utils.ErrCodeTimeout)
+	}
+	if result.DownloadMbps != 0 {
+		t.Errorf("DownloadMbps = %f, want 0", result.DownloadMbps)
+	}
+}
@@ -0,0 +1,30 @@
+package modules
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the instrumentation name used for spans created by the test modules
+const TracerName = "github.com/ehsanghaffar/ultimate-internet-test/modules"
+
+// startSpan starts a child span of ctx using the globally registered tracer provider.
+// A no-op span is returned when no provider has been configured.
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(TracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan records the response time and error of a test on span and ends it
+func endSpan(span trace.Span, start time.Time, errMsg string) {
+	span.SetAttributes(attribute.Int64("response_time_ms", time.Since(start).Milliseconds()))
+	if errMsg != "" {
+		span.SetAttributes(attribute.String("error", errMsg))
+		span.SetStatus(codes.Error, errMsg)
+	}
+	span.End()
+}
@@ -0,0 +1,207 @@
+package modules
+
+import (
+	"context"
+	"crypto/tls"
+	"log"
+	"net"
+	"net/http"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/proxy"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+)
+
+// newDialer returns a dialer bound to the configured source IP or local interface, if any
+func newDialer(cfg *config.Config) (*net.Dialer, error) {
+	dialer := &net.Dialer{
+		Timeout:  cfg.HTTPTimeout,
+		Resolver: connectionResolver(cfg),
+	}
+
+	if cfg.SourceIP != "" {
+		ip := net.ParseIP(cfg.SourceIP)
+		if ip == nil {
+			return nil, utils.NewValidationError("Config", utils.ErrCodeValidation, "invalid source IP: "+cfg.SourceIP)
+		}
+		dialer.LocalAddr = &net.TCPAddr{IP: ip}
+	} else if cfg.LocalInterface != "" {
+		addr, err := utils.ResolveInterfaceAddr(cfg.LocalInterface)
+		if err != nil {
+			return nil, err
+		}
+		dialer.LocalAddr = addr
+	}
+
+	return dialer, nil
+}
+
+// newUDPDialer is like newDialer but binds a UDP local address, since the dialer
+// rejects a TCP local address for UDP connections
+func newUDPDialer(cfg *config.Config) (*net.Dialer, error) {
+	dialer, err := newDialer(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if addr, ok := dialer.LocalAddr.(*net.TCPAddr); ok {
+		dialer.LocalAddr = &net.UDPAddr{IP: addr.IP, Zone: addr.Zone}
+	}
+
+	return dialer, nil
+}
+
+// dialFunc dials a network connection like net.Dialer.DialContext
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// newTCPDialFunc returns the dial function for TCP connections: through cfg.SOCKS5Proxy when set,
+// which is reached with the configured dialer, and directly with that dialer otherwise
+func newTCPDialFunc(cfg *config.Config) (dialFunc, error) {
+	dialer, err := newDialer(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.SOCKS5Proxy == "" {
+		return dialer.DialContext, nil
+	}
+
+	socks, err := proxy.SOCKS5("tcp", cfg.SOCKS5Proxy, nil, dialer)
+	if err != nil {
+		return nil, utils.NewValidationError("Config", utils.ErrCodeValidation, "invalid SOCKS5 proxy: "+err.Error())
+	}
+	return socks.(proxy.ContextDialer).DialContext, nil
+}
+
+// warnBypassesProxy logs that a UDP or ICMP based test does not go through the configured
+// SOCKS5 proxy, since only TCP is proxied and SOCKS5 UDP associate is not implemented
+func warnBypassesProxy(test string, cfg *config.Config) {
+	if cfg.SOCKS5Proxy != "" {
+		log.Println("Warning:", test, "bypasses the SOCKS5 proxy, only TCP connections are proxied")
+	}
+}
+
+// setUserAgent sets the configured User-Agent on req, keeping Go's default when none is configured
+func setUserAgent(req *http.Request, cfg *config.Config) {
+	if cfg.UserAgent != "" {
+		req.Header.Set("User-Agent", cfg.UserAgent)
+	}
+}
+
+// newTransport returns an HTTP transport based on http.DefaultTransport with the configured dialer.
+// With a SOCKS5 proxy the HTTP proxy environment variables are ignored.
+func newTransport(cfg *config.Config) (*http.Transport, error) {
+	dial, err := newTCPDialFunc(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dial
+	if cfg.SOCKS5Proxy != "" {
+		transport.Proxy = nil
+	}
+	transport.TLSClientConfig = &tls.Config{
+		MinVersion: cfg.TLSMinVersion,
+		MaxVersion: cfg.TLSMaxVersion,
+	}
+
+	return transport, nil
+}
+
+// newHTTPRoundTripper returns the round tripper for HTTP tests, forcing cfg.HTTPVersion when set.
+// HTTP/1.1 disables the HTTP/2 upgrade over ALPN, HTTP/2 uses an HTTP/2-only transport that fails
+// on servers without h2, and HTTP/3 uses a QUIC transport that fails on servers without h3.
+func newHTTPRoundTripper(cfg *config.Config) (http.RoundTripper, error) {
+	transport, err := newTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	switch cfg.HTTPVersion {
+	case "":
+		return transport, nil
+	case utils.HTTPVersion11:
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+		return transport, nil
+	case utils.HTTPVersion2:
+		tlsConfig := transport.TLSClientConfig.Clone()
+		tlsConfig.NextProtos = []string{http2.NextProtoTLS}
+		return &http2.Transport{
+			TLSClientConfig: tlsConfig,
+			DialTLSContext: func(ctx context.Context, network, addr string, tlsConfig *tls.Config) (net.Conn, error) {
+				conn, err := transport.DialContext(ctx, network, addr)
+				if err != nil {
+					return nil, err
+				}
+				tlsConn := tls.Client(conn, tlsConfig)
+				if err := tlsConn.HandshakeContext(ctx); err != nil {
+					conn.Close()
+					return nil, err
+				}
+				return tlsConn, nil
+			},
+		}, nil
+	case utils.HTTPVersion3:
+		return newHTTP3RoundTripper(cfg, transport.TLSClientConfig.Clone())
+	}
+
+	return nil, utils.ValidateHTTPVersion(cfg.HTTPVersion)
+}
+
+// newHTTP3RoundTripper returns an HTTP/3 round tripper with the given TLS settings. Its QUIC
+// connections resolve hosts with the connection resolver and are bound to the configured source
+// IP or local interface. QUIC runs over UDP, so it bypasses a SOCKS5 proxy.
+func newHTTP3RoundTripper(cfg *config.Config, tlsConfig *tls.Config) (http.RoundTripper, error) {
+	dialer, err := newUDPDialer(cfg)
+	if err != nil {
+		return nil, err
+	}
+	warnBypassesProxy("HTTP/3", cfg)
+
+	return &http3.RoundTripper{
+		TLSClientConfig: tlsConfig,
+		Dial: func(ctx context.Context, addr string, tlsCfg *tls.Config, quicCfg *quic.Config) (quic.EarlyConnection, error) {
+			host, port, err := net.SplitHostPort(addr)
+			if err != nil {
+				return nil, err
+			}
+			ips, err := dialer.Resolver.LookupIPAddr(ctx, host)
+			if err != nil {
+				return nil, err
+			}
+			if len(ips) == 0 {
+				return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+			}
+			remote, err := net.ResolveUDPAddr("udp", net.JoinHostPort(ips[0].String(), port))
+			if err != nil {
+				return nil, err
+			}
+
+			var local *net.UDPAddr
+			if addr, ok := dialer.LocalAddr.(*net.UDPAddr); ok {
+				local = addr
+			}
+			packetConn, err := net.ListenUDP("udp", local)
+			if err != nil {
+				return nil, err
+			}
+
+			conn, err := quic.DialEarly(ctx, packetConn, remote, tlsCfg, quicCfg)
+			if err != nil {
+				packetConn.Close()
+				return nil, err
+			}
+			// quic-go leaves a packet conn it was given open, so close it with the connection
+			go func() {
+				<-conn.Context().Done()
+				packetConn.Close()
+			}()
+			return conn, nil
+		},
+	}, nil
+}
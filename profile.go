@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"runtime/pprof"
+)
+
+// Profile kinds accepted by --profile
+const (
+	profileCPU = "cpu"
+	profileMem = "mem"
+)
+
+// startProfiling starts the profile of the given kind and returns a function that
+// finishes it. An empty path writes to cpu.pprof or mem.pprof.
+func startProfiling(kind string, path string) (func(), error) {
+	if kind != profileCPU && kind != profileMem {
+		return nil, fmt.Errorf("unknown profile %q (want cpu or mem)", kind)
+	}
+	if path == "" {
+		path = kind + ".pprof"
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if kind == profileCPU {
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return nil, err
+		}
+		return func() {
+			pprof.StopCPUProfile()
+			f.Close()
+			log.Println("CPU profile written to", path)
+		}, nil
+	}
+
+	// The heap profile is a snapshot, so it is taken once the tests are done
+	return func() {
+		defer f.Close()
+		runtime.GC()
+		if err := pprof.WriteHeapProfile(f); err != nil {
+			log.Printf("Error writing heap profile: %v\n", err)
+			return
+		}
+		log.Println("Heap profile written to", path)
+	}, nil
+}
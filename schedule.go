@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// waitUntilScheduled sleeps until the next occurrence of clock ("15:04", 24h format) in the named time zone
+func waitUntilScheduled(clock string, tz string) error {
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return fmt.Errorf("invalid time zone %q: %w", tz, err)
+	}
+
+	next, err := nextOccurrence(clock, time.Now().In(loc))
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Waiting until %s (%s) to run tests\n", next.Format("2006-01-02 15:04 MST"), time.Until(next).Round(time.Second))
+	time.Sleep(time.Until(next))
+	return nil
+}
+
+// nextOccurrence returns the next time after now at which the wall clock in now's location
+// shows clock: today if that is still in the future, tomorrow otherwise
+func nextOccurrence(clock string, now time.Time) (time.Time, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, want HH:MM in 24h format", clock)
+	}
+
+	next := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, now.Location())
+	if !next.After(now) {
+		next = time.Date(now.Year(), now.Month(), now.Day()+1, t.Hour(), t.Minute(), 0, 0, now.Location())
+	}
+	return next, nil
+}
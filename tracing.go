@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+// serviceName identifies this tool in exported traces
+const serviceName = "ultimate-internet-test"
+
+// initTracing installs an OTLP trace exporter when OTEL_EXPORTER_OTLP_ENDPOINT is set.
+// Otherwise the global no-op tracer stays in place. The returned function flushes pending spans.
+func initTracing(ctx context.Context) func() {
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" {
+		return func() {}
+	}
+
+	// The exporter reads its endpoint and headers from the standard OTEL_* environment variables
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		log.Printf("Error creating OTLP exporter, tracing disabled: %v\n", err)
+		return func() {}
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceNameKey.String(serviceName),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func() {
+		if err := provider.Shutdown(ctx); err != nil {
+			log.Printf("Error flushing traces: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// AggregateSpeedTests computes download speed statistics across speed tests.
+// Failed tests are left out. Percentiles interpolate linearly between the closest ranks,
+// and the standard deviation is the population standard deviation.
+func AggregateSpeedTests(tests []SpeedTest) SpeedTestAggregate {
+	var agg SpeedTestAggregate
+
+	speeds := make([]float64, 0, len(tests))
+	for _, t := range tests {
+		if t.Error != "" {
+			continue
+		}
+		speeds = append(speeds, t.DownloadMbps)
+		agg.TotalBytesReceived += int64(t.BytesReceived)
+	}
+
+	agg.Count = len(speeds)
+	if agg.Count == 0 {
+		return agg
+	}
+
+	sort.Float64s(speeds)
+	agg.MinMbps = speeds[0]
+	agg.MaxMbps = speeds[len(speeds)-1]
+	agg.MedianMbps = percentile(speeds, 50)
+	agg.P95Mbps = percentile(speeds, 95)
+
+	var sum float64
+	for _, s := range speeds {
+		sum += s
+	}
+	agg.MeanMbps = sum / float64(agg.Count)
+
+	var variance float64
+	for _, s := range speeds {
+		variance += (s - agg.MeanMbps) * (s - agg.MeanMbps)
+	}
+	agg.StdDevMbps = math.Sqrt(variance / float64(agg.Count))
+
+	return agg
+}
+
+// percentile returns the p-th percentile of sorted, which must not be empty
+func percentile(sorted []float64, p float64) float64 {
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+// NewHTTPTestStats computes response time statistics across repeated HTTP tests of url
+func NewHTTPTestStats(url string, runs []HTTPTest) *HTTPTestStats {
+	stats := &HTTPTestStats{
+		URL:  url,
+		Runs: len(runs),
+	}
+
+	var total time.Duration
+	successes := 0
+	for _, r := range runs {
+		if r.Error != "" {
+			stats.ErrorCount++
+			continue
+		}
+		if successes == 0 || r.TotalTime < stats.MinResponseTime {
+			stats.MinResponseTime = r.TotalTime
+		}
+		if r.TotalTime > stats.MaxResponseTime {
+			stats.MaxResponseTime = r.TotalTime
+		}
+		total += r.TotalTime
+		successes++
+	}
+
+	if successes > 0 {
+		stats.AvgResponseTime = total / time.Duration(successes)
+	}
+
+	return stats
+}
+
+// NewSpeedTestStats computes download speed statistics across repeated speed tests of url
+func NewSpeedTestStats(url string, runs []SpeedTest) *SpeedTestStats {
+	stats := &SpeedTestStats{
+		URL:  url,
+		Runs: len(runs),
+	}
+
+	agg := AggregateSpeedTests(runs)
+	stats.ErrorCount = len(runs) - agg.Count
+	stats.MinMbps = agg.MinMbps
+	stats.MaxMbps = agg.MaxMbps
+	stats.AvgMbps = agg.MeanMbps
+
+	return stats
+}
+
+// AggregateLatencies computes the latency distribution of samples, which must not be empty.
+// Like AggregateSpeedTests it uses the population standard deviation and interpolated percentiles.
+func AggregateLatencies(samples []time.Duration) (mean, stdDev, p95 time.Duration, cv float64) {
+	sorted := make([]float64, len(samples))
+	var sum float64
+	for i, s := range samples {
+		sorted[i] = float64(s)
+		sum += float64(s)
+	}
+	sort.Float64s(sorted)
+
+	m := sum / float64(len(sorted))
+	var variance float64
+	for _, s := range sorted {
+		variance += (s - m) * (s - m)
+	}
+	sd := math.Sqrt(variance / float64(len(sorted)))
+
+	if m > 0 {
+		cv = sd / m
+	}
+	return time.Duration(m), time.Duration(sd), time.Duration(percentile(sorted, 95)), cv
+}
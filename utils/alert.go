@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+)
+
+// FailedTests describes every failed HTTP, DNS, speed, VPN and ping test in results, one line per test
+func FailedTests(results *TestResults) []string {
+	var failed []string
+	for _, t := range results.HTTPTests {
+		if t.Error != "" {
+			failed = append(failed, "HTTP "+t.URL+": "+t.Error)
+		}
+	}
+	for _, t := range results.DNSTests {
+		if t.Error != "" {
+			failed = append(failed, "DNS "+t.Domain+": "+t.Error)
+		}
+	}
+	for _, t := range results.SpeedTests {
+		if t.Error != "" {
+			failed = append(failed, "Speed "+t.URL+": "+t.Error)
+		}
+	}
+	if results.VPNTest.Error != "" {
+		failed = append(failed, "VPN: "+results.VPNTest.Error)
+	}
+	if results.PingTest.Error != "" {
+		failed = append(failed, "Ping "+results.PingTest.URL+": "+results.PingTest.Error)
+	}
+	return failed
+}
+
+// SendAlertEmail emails a plain-text summary of results to cfg.AlertEmail when any test failed or
+// the ping packet loss exceeds cfg.AlertPacketLossThreshold. Nothing is sent when all is well.
+// cfg.AlertEmail may list several comma separated recipients; the SMTP server is authenticated
+// with PLAIN auth when cfg.AlertSMTPUser is set.
+func SendAlertEmail(cfg *config.Config, results *TestResults) error {
+	if cfg.AlertEmail == "" || cfg.AlertSMTPHost == "" {
+		return NewValidationError("Alert", ErrCodeValidation, "alert email recipient and SMTP host are required")
+	}
+
+	failed := FailedTests(results)
+	ping := results.PingTest
+	lossExceeded := ping.URL != "" && ping.Error == "" && cfg.AlertPacketLossThreshold > 0 && ping.Loss > cfg.AlertPacketLossThreshold
+	if len(failed) == 0 && !lossExceeded {
+		return nil
+	}
+
+	var recipients []string
+	for _, r := range strings.Split(cfg.AlertEmail, ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			recipients = append(recipients, r)
+		}
+	}
+
+	from := cfg.AlertSMTPUser
+	if !strings.Contains(from, "@") {
+		host, err := os.Hostname()
+		if err != nil {
+			host = "localhost"
+		}
+		from = "ultimate-internet-test@" + host
+	}
+
+	runAt := results.Timestamp
+	if runAt.IsZero() {
+		runAt = time.Now()
+	}
+
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "Internet test run at %s\r\n\r\n", runAt.Format(time.RFC1123))
+	if len(failed) > 0 {
+		fmt.Fprintf(&body, "%d test(s) failed:\r\n", len(failed))
+		for _, f := range failed {
+			fmt.Fprintf(&body, "  - %s\r\n", f)
+		}
+		body.WriteString("\r\n")
+	}
+	if lossExceeded {
+		fmt.Fprintf(&body, "Packet loss to %s is %.1f%% (threshold %.1f%%)\r\n",
+			ping.URL, ping.Loss, cfg.AlertPacketLossThreshold)
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(recipients, ", "))
+	fmt.Fprintf(&msg, "Subject: Internet test alert: %d failed test(s)\r\n", len(failed))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.Write(body.Bytes())
+
+	var auth smtp.Auth
+	if cfg.AlertSMTPUser != "" {
+		auth = smtp.PlainAuth("", cfg.AlertSMTPUser, cfg.AlertSMTPPassword, cfg.AlertSMTPHost)
+	}
+
+	addr := net.JoinHostPort(cfg.AlertSMTPHost, strconv.Itoa(cfg.AlertSMTPPort))
+	if err := smtp.SendMail(addr, auth, from, recipients, msg.Bytes()); err != nil {
+		return NewNetworkError("Alert", ErrCodeNetwork, "failed to send alert email", err)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// cloudUploadTimeout bounds an upload of results to object storage
+const cloudUploadTimeout = 2 * time.Minute
+
+// ResultUploader uploads test results to remote object storage
+type ResultUploader interface {
+	// Upload uploads results as indented JSON
+	Upload(ctx context.Context, results *TestResults) error
+}
+
+// NewS3Uploader returns a ResultUploader that uploads results to key in an S3 bucket.
+// Credentials, region and endpoint come from the standard AWS SDK sources: the AWS_* environment
+// variables, the shared config and credentials files (AWS_PROFILE), web identity (IRSA) and
+// instance roles.
+func NewS3Uploader(bucket, key string) ResultUploader {
+	return s3Uploader{bucket: bucket, key: key}
+}
+
+// NewGCSUploader returns a ResultUploader that uploads results to object in a GCS bucket.
+// Credentials are Google application default credentials; STORAGE_EMULATOR_HOST selects an emulator.
+func NewGCSUploader(bucket, object string) ResultUploader {
+	return gcsUploader{bucket: bucket, object: object}
+}
+
+// s3Uploader uploads results with the S3 upload manager, which switches to multipart uploads for large files
+type s3Uploader struct {
+	bucket string
+	key    string
+}
+
+func (s s3Uploader) Upload(ctx context.Context, results *TestResults) error {
+	data, err := encodeCloudResults(results)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cloudUploadTimeout)
+	defer cancel()
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return NewValidationError("Storage", ErrCodeValidation, "cannot load AWS configuration: "+err.Error())
+	}
+
+	uploader := manager.NewUploader(s3.NewFromConfig(awsCfg))
+	if _, err := uploader.Upload(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(s.key),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String("application/json"),
+	}); err != nil {
+		return NewNetworkError("Storage", ErrCodeNetwork, "failed to upload results to S3", err)
+	}
+	return nil
+}
+
+// gcsUploader uploads results with the Cloud Storage client
+type gcsUploader struct {
+	bucket string
+	object string
+}
+
+func (g gcsUploader) Upload(ctx context.Context, results *TestResults) error {
+	data, err := encodeCloudResults(results)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cloudUploadTimeout)
+	defer cancel()
+
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return NewValidationError("Storage", ErrCodeValidation, "cannot create GCS client: "+err.Error())
+	}
+	defer client.Close()
+
+	writer := client.Bucket(g.bucket).Object(g.object).NewWriter(ctx)
+	writer.ContentType = "application/json"
+	if _, err := writer.Write(data); err != nil {
+		writer.Close()
+		return NewNetworkError("Storage", ErrCodeNetwork, "failed to upload results to GCS", err)
+	}
+	if err := writer.Close(); err != nil {
+		return NewNetworkError("Storage", ErrCodeNetwork, "failed to upload results to GCS", err)
+	}
+	return nil
+}
+
+// encodeCloudResults encodes results as indented JSON
+func encodeCloudResults(results *TestResults) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := WriteResultsTo(results, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+)
+
+// GzipExtension is the file extension that makes SaveResults compress its output
+const GzipExtension = ".json.gz"
+
+// gzipMagic are the first two bytes of every gzip stream
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// CompressResults marshals test results to JSON and gzip compresses them
+func CompressResults(results *TestResults) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+
+	if err := WriteResultsTo(results, zw); err != nil {
+		return nil, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, NewParseError("Storage", ErrCodeParse, "failed to compress results", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// DecompressResults decompresses gzip data produced by CompressResults and parses and validates the results
+func DecompressResults(data []byte) (*TestResults, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, NewParseError("Storage", ErrCodeParse, "failed to decompress results", err)
+	}
+	defer zr.Close()
+
+	return NewTestResultsFromReader(zr)
+}
+
+// isGzip reports whether data starts with the gzip magic bytes
+func isGzip(data []byte) bool {
+	return bytes.HasPrefix(data, gzipMagic)
+}
+
+// isGzipPath reports whether results saved to filePath should be compressed
+func isGzipPath(filePath string) bool {
+	return strings.HasSuffix(strings.ToLower(filePath), GzipExtension)
+}
+
+// gunzipAll decompresses data when it is gzip compressed and returns it unchanged otherwise
+func gunzipAll(data []byte) ([]byte, error) {
+	if !isGzip(data) {
+		return data, nil
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, NewParseError("Storage", ErrCodeParse, "failed to decompress results", err)
+	}
+	defer zr.Close()
+
+	out, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, NewParseError("Storage", ErrCodeParse, "failed to decompress results", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strconv"
+	"time"
+)
+
+// csvHeader lists the columns written by MarshalCSV
+var csvHeader = []string{
+	"type", "timestamp", "url", "status", "error",
+	"proto", "tls_version", "cipher_suite", "server_name", "response_length",
+	"download_mbps", "elapsed_time", "bytes_received",
+	"transmitted_packets", "received_packets", "loss_packets",
+}
+
+// MarshalCSV serializes the results as a flat CSV table with one row per test.
+// The "type" column identifies the test kind and columns that do not apply to it are left empty.
+func (r *TestResults) MarshalCSV() ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	if err := w.Write(csvHeader); err != nil {
+		return nil, NewParseError("CSV", ErrCodeParse, "failed to write header", err)
+	}
+
+	timestamp := ""
+	if !r.Timestamp.IsZero() {
+		timestamp = r.Timestamp.Format(time.RFC3339)
+	}
+
+	var rows [][]string
+
+	for _, t := range r.HTTPTests {
+		row := newCSVRow("http", timestamp, t.URL, t.Status, t.Error)
+		row[5] = t.Proto
+		row[6] = t.TLSVersion
+		row[7] = t.CipherSuite
+		row[8] = t.ServerName
+		row[9] = strconv.Itoa(t.ResponseLength)
+		rows = append(rows, row)
+	}
+
+	for _, t := range r.SpeedTests {
+		row := newCSVRow("speed", timestamp, t.URL, "", t.Error)
+		row[10] = strconv.FormatFloat(t.DownloadMbps, 'f', 2, 64)
+		row[11] = t.ElapsedTime.String()
+		row[12] = strconv.Itoa(t.BytesReceived)
+		rows = append(rows, row)
+	}
+
+	if r.PingTest.URL != "" || r.PingTest.Error != "" {
+		t := r.PingTest
+		row := newCSVRow("ping", timestamp, t.URL, "", t.Error)
+		row[13] = strconv.Itoa(t.Transmitted)
+		row[14] = strconv.Itoa(t.Received)
+		row[15] = strconv.FormatFloat(t.Loss, 'f', 2, 64)
+		rows = append(rows, row)
+	}
+
+	if r.VPNTest.Status != "" || r.VPNTest.Error != "" {
+		rows = append(rows, newCSVRow("vpn", timestamp, "", r.VPNTest.Status, r.VPNTest.Error))
+	}
+
+	if err := w.WriteAll(rows); err != nil {
+		return nil, NewParseError("CSV", ErrCodeParse, "failed to write rows", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// newCSVRow returns a row with the common columns populated and the rest empty
+func newCSVRow(testType, timestamp, url, status, errMsg string) []string {
+	row := make([]string, len(csvHeader))
+	row[0] = testType
+	row[1] = timestamp
+	row[2] = url
+	row[3] = status
+	row[4] = errMsg
+	return row
+}
@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"math"
+	"sort"
+)
+
+// MetricDelta describes how a single metric changed between two test runs
+type MetricDelta struct {
+	Metric         string  `json:"metric"`
+	Baseline       float64 `json:"baseline"`
+	Current        float64 `json:"current"`
+	ChangePct      float64 `json:"change_pct"`
+	HigherIsBetter bool    `json:"higher_is_better"`
+}
+
+// Regressed reports whether the metric worsened by more than tolerancePct percent
+func (d MetricDelta) Regressed(tolerancePct float64) bool {
+	if d.HigherIsBetter {
+		return d.ChangePct < -tolerancePct
+	}
+	return d.ChangePct > tolerancePct
+}
+
+// DiffResults compares the metrics of two test runs. Only tests present in both runs are compared.
+// Deltas are sorted by metric name.
+func DiffResults(baseline, current *TestResults) []MetricDelta {
+	var deltas []MetricDelta
+
+	baseHTTP := make(map[string]HTTPTest, len(baseline.HTTPTests))
+	for _, t := range baseline.HTTPTests {
+		baseHTTP[t.URL] = t
+	}
+	for _, t := range current.HTTPTests {
+		if b, ok := baseHTTP[t.URL]; ok {
+			deltas = append(deltas, newMetricDelta("http."+t.URL+".success", successValue(b.Error), successValue(t.Error), true))
+		}
+	}
+
+	baseSpeed := make(map[string]SpeedTest, len(baseline.SpeedTests))
+	for _, t := range baseline.SpeedTests {
+		baseSpeed[t.URL] = t
+	}
+	for _, t := range current.SpeedTests {
+		if b, ok := baseSpeed[t.URL]; ok {
+			deltas = append(deltas, newMetricDelta("speed."+t.URL+".download_mbps", b.DownloadMbps, t.DownloadMbps, true))
+		}
+	}
+
+	if baseline.PingTest.URL != "" && baseline.PingTest.URL == current.PingTest.URL {
+		deltas = append(deltas, newMetricDelta("ping."+current.PingTest.URL+".loss_pct", baseline.PingTest.Loss, current.PingTest.Loss, false))
+	}
+
+	sort.Slice(deltas, func(i, j int) bool {
+		return deltas[i].Metric < deltas[j].Metric
+	})
+
+	return deltas
+}
+
+// newMetricDelta computes the percent change from baseline to current
+func newMetricDelta(metric string, baseline, current float64, higherIsBetter bool) MetricDelta {
+	d := MetricDelta{
+		Metric:         metric,
+		Baseline:       baseline,
+		Current:        current,
+		HigherIsBetter: higherIsBetter,
+	}
+
+	switch {
+	case baseline != 0:
+		d.ChangePct = (current - baseline) / math.Abs(baseline) * 100
+	case current > 0:
+		d.ChangePct = math.Inf(1)
+	case current < 0:
+		d.ChangePct = math.Inf(-1)
+	}
+
+	return d
+}
+
+// successValue maps a test error to 1 (success) or 0 (failure)
+func successValue(errMsg string) float64 {
+	if errMsg == "" {
+		return 1
+	}
+	return 0
+}
+
+// BodyHashChange describes an HTTP test whose response body hash differs between two runs
+type BodyHashChange struct {
+	URL      string
+	Previous string
+	Current  string
+}
+
+// ChangedBodyHashes returns the HTTP tests of current whose ResponseBodyHash differs from the
+// test of the same URL in previous. Tests without a hash in either run are not compared.
+func ChangedBodyHashes(previous, current *TestResults) []BodyHashChange {
+	prevHash := make(map[string]string, len(previous.HTTPTests))
+	for _, t := range previous.HTTPTests {
+		if t.ResponseBodyHash != "" {
+			prevHash[t.URL] = t.ResponseBodyHash
+		}
+	}
+
+	var changes []BodyHashChange
+	for _, t := range current.HTTPTests {
+		if h, ok := prevHash[t.URL]; ok && t.ResponseBodyHash != "" && t.ResponseBodyHash != h {
+			changes = append(changes, BodyHashChange{URL: t.URL, Previous: h, Current: t.ResponseBodyHash})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,262 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// DNSCache is an in-memory cache of DNS lookups keyed by host name and record type
+type DNSCache struct {
+	// TTL is how long a lookup stays cached
+	TTL time.Duration
+
+	entries sync.Map
+}
+
+// dnsCacheKey identifies a cached lookup
+type dnsCacheKey struct {
+	host       string
+	recordType string
+}
+
+// dnsCacheEntry is a cached lookup and the time it expires
+type dnsCacheEntry struct {
+	addrs   []string
+	expires time.Time
+}
+
+// NewDNSCache creates an empty DNS cache whose entries live for ttl
+func NewDNSCache(ttl time.Duration) *DNSCache {
+	return &DNSCache{TTL: ttl}
+}
+
+// Get returns the cached addresses of host for recordType ("A" or "AAAA") and when they expire.
+// The last result is false when nothing is cached or the entry expired.
+func (c *DNSCache) Get(host, recordType string) ([]string, time.Time, bool) {
+	key := dnsCacheKey{host: strings.ToLower(host), recordType: recordType}
+
+	value, ok := c.entries.Load(key)
+	if !ok {
+		return nil, time.Time{}, false
+	}
+
+	entry := value.(dnsCacheEntry)
+	if time.Now().After(entry.expires) {
+		c.entries.Delete(key)
+		return nil, time.Time{}, false
+	}
+
+	return entry.addrs, entry.expires, true
+}
+
+// Set caches the addresses of host for recordType and returns when they expire
+func (c *DNSCache) Set(host, recordType string, addrs []string) time.Time {
+	expires := time.Now().Add(c.TTL)
+	c.entries.Store(dnsCacheKey{host: strings.ToLower(host), recordType: recordType}, dnsCacheEntry{
+		addrs:   addrs,
+		expires: expires,
+	})
+	return expires
+}
+
+// NewCachedResolver returns a resolver that answers A and AAAA lookups from cache,
+// falling back to upstream on a miss. Other record types are answered with no records.
+//
+// The returned resolver uses the pure Go resolver with a Dial function that never touches the
+// network: every DNS query is answered in-process, either from cache or from an upstream lookup.
+//
+// Example:
+//
+//	cache := NewDNSCache(5 * time.Minute)
+//	dialer := &net.Dialer{Resolver: NewCachedResolver(cache, net.DefaultResolver)}
+func NewCachedResolver(cache *DNSCache, upstream *net.Resolver) *net.Resolver {
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return &cachedDNSConn{ctx: ctx, cache: cache, upstream: upstream}, nil
+		},
+	}
+}
+
+// cachedDNSConn is an in-process DNS "connection". It is a stream connection, so the Go
+// resolver frames every message with a two byte length prefix regardless of network.
+type cachedDNSConn struct {
+	ctx      context.Context
+	cache    *DNSCache
+	upstream *net.Resolver
+
+	mu       sync.Mutex
+	request  bytes.Buffer
+	response bytes.Buffer
+}
+
+// Write collects query messages and answers each complete one
+func (c *cachedDNSConn) Write(b []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.request.Write(b)
+	for c.request.Len() >= 2 {
+		size := int(binary.BigEndian.Uint16(c.request.Bytes()))
+		if c.request.Len() < 2+size {
+			break
+		}
+		c.request.Next(2)
+		query := c.request.Next(size)
+
+		answer, err := c.answer(query)
+		if err != nil {
+			return 0, err
+		}
+
+		var length [2]byte
+		binary.BigEndian.PutUint16(length[:], uint16(len(answer)))
+		c.response.Write(length[:])
+		c.response.Write(answer)
+	}
+
+	return len(b), nil
+}
+
+// Read returns the pending answers
+func (c *cachedDNSConn) Read(b []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.response.Len() == 0 {
+		return 0, io.EOF
+	}
+	return c.response.Read(b)
+}
+
+// answer builds the response to a single wire format query
+func (c *cachedDNSConn) answer(query []byte) ([]byte, error) {
+	var parser dnsmessage.Parser
+	header, err := parser.Start(query)
+	if err != nil {
+		return nil, err
+	}
+	question, err := parser.Question()
+	if err != nil {
+		return nil, err
+	}
+
+	builder := dnsmessage.NewBuilder(nil, dnsmessage.Header{
+		ID:                 header.ID,
+		Response:           true,
+		RecursionDesired:   header.RecursionDesired,
+		RecursionAvailable: true,
+	})
+	builder.EnableCompression()
+	if err := builder.StartQuestions(); err != nil {
+		return nil, err
+	}
+	if err := builder.Question(question); err != nil {
+		return nil, err
+	}
+
+	var recordType, network string
+	switch question.Type {
+	case dnsmessage.TypeA:
+		recordType, network = "A", "ip4"
+	case dnsmessage.TypeAAAA:
+		recordType, network = "AAAA", "ip6"
+	default:
+		return builder.Finish()
+	}
+
+	host := strings.TrimSuffix(question.Name.String(), ".")
+	addrs, expires, ok := c.cache.Get(host, recordType)
+	if !ok {
+		addrs, err = c.lookup(network, host)
+		if err != nil {
+			// Temporary failures are not cached so the next lookup retries
+			builder = dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: header.ID, Response: true, RCode: dnsmessage.RCodeServerFailure})
+			return builder.Finish()
+		}
+		expires = c.cache.Set(host, recordType, addrs)
+	}
+
+	if err := builder.StartAnswers(); err != nil {
+		return nil, err
+	}
+
+	rh := dnsmessage.ResourceHeader{
+		Name:  question.Name,
+		Class: dnsmessage.ClassINET,
+		TTL:   uint32(time.Until(expires).Seconds()),
+	}
+	for _, addr := range addrs {
+		ip := net.ParseIP(addr)
+		if ip4 := ip.To4(); recordType == "A" && ip4 != nil {
+			var r dnsmessage.AResource
+			copy(r.A[:], ip4)
+			if err := builder.AResource(rh, r); err != nil {
+				return nil, err
+			}
+		} else if recordType == "AAAA" && ip != nil {
+			var r dnsmessage.AAAAResource
+			copy(r.AAAA[:], ip.To16())
+			if err := builder.AAAAResource(rh, r); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return builder.Finish()
+}
+
+// lookup resolves host through the upstream resolver. A host without records of the
+// requested family yields an empty list, which is cached like any other answer.
+func (c *cachedDNSConn) lookup(network, host string) ([]string, error) {
+	ips, err := c.upstream.LookupIP(c.ctx, network, host)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	addrs := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		addrs = append(addrs, ip.String())
+	}
+	return addrs, nil
+}
+
+// Close implements net.Conn
+func (c *cachedDNSConn) Close() error { return nil }
+
+// LocalAddr implements net.Conn
+func (c *cachedDNSConn) LocalAddr() net.Addr { return cachedDNSAddr{} }
+
+// RemoteAddr implements net.Conn
+func (c *cachedDNSConn) RemoteAddr() net.Addr { return cachedDNSAddr{} }
+
+// SetDeadline implements net.Conn; answers never block, so deadlines are ignored
+func (c *cachedDNSConn) SetDeadline(time.Time) error { return nil }
+
+// SetReadDeadline implements net.Conn
+func (c *cachedDNSConn) SetReadDeadline(time.Time) error { return nil }
+
+// SetWriteDeadline implements net.Conn
+func (c *cachedDNSConn) SetWriteDeadline(time.Time) error { return nil }
+
+// cachedDNSAddr is the address of an in-process DNS connection
+type cachedDNSAddr struct{}
+
+// Network implements net.Addr
+func (cachedDNSAddr) Network() string { return "dnscache" }
+
+// String implements net.Addr
+func (cachedDNSAddr) String() string { return "dnscache" }
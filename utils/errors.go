@@ -1,12 +1,48 @@
 package utils
 
-import "fmt"
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Error codes classify test failures for programmatic handling
+const (
+	// ErrCodeNone indicates no error
+	ErrCodeNone = iota
+
+	// ErrCodeNetwork indicates a connection or I/O failure
+	ErrCodeNetwork
+
+	// ErrCodeTimeout indicates an operation exceeded its deadline
+	ErrCodeTimeout
+
+	// ErrCodeTLS indicates a TLS handshake or certificate failure
+	ErrCodeTLS
+
+	// ErrCodeDNS indicates a name resolution failure
+	ErrCodeDNS
+
+	// ErrCodeHTTP indicates an HTTP protocol level failure
+	ErrCodeHTTP
+
+	// ErrCodeParse indicates malformed data
+	ErrCodeParse
+
+	// ErrCodeValidation indicates invalid input or configuration
+	ErrCodeValidation
+)
 
 // TestError represents an error that occurred during testing
 type TestError struct {
-	TestType string // The type of test that failed (e.g., "HTTP", "Ping", "Speed", "VPN")
-	Message  string
-	Err      error // The underlying error
+	TestType  string // The type of test that failed (e.g., "HTTP", "Ping", "Speed", "VPN")
+	ErrorCode int    // One of the ErrCode* constants
+	Message   string
+	Err       error // The underlying error
 }
 
 // Error implements the error interface
@@ -22,12 +58,18 @@ func (e *TestError) Unwrap() error {
 	return e.Err
 }
 
+// Code returns the error code of the test error
+func (e *TestError) Code() int {
+	return e.ErrorCode
+}
+
 // NewTestError creates a new TestError
-func NewTestError(testType, message string, err error) *TestError {
+func NewTestError(testType string, code int, message string, err error) *TestError {
 	return &TestError{
-		TestType: testType,
-		Message:  message,
-		Err:      err,
+		TestType:  testType,
+		ErrorCode: code,
+		Message:   message,
+		Err:       err,
 	}
 }
 
@@ -37,9 +79,9 @@ type NetworkError struct {
 }
 
 // NewNetworkError creates a new NetworkError
-func NewNetworkError(testType, message string, err error) *NetworkError {
+func NewNetworkError(testType string, code int, message string, err error) *NetworkError {
 	return &NetworkError{
-		TestError: NewTestError(testType, message, err),
+		TestError: NewTestError(testType, code, message, err),
 	}
 }
 
@@ -49,9 +91,9 @@ type TimeoutError struct {
 }
 
 // NewTimeoutError creates a new TimeoutError
-func NewTimeoutError(testType, message string) *TimeoutError {
+func NewTimeoutError(testType string, code int, message string) *TimeoutError {
 	return &TimeoutError{
-		TestError: NewTestError(testType, message, nil),
+		TestError: NewTestError(testType, code, message, nil),
 	}
 }
 
@@ -61,9 +103,9 @@ type ValidationError struct {
 }
 
 // NewValidationError creates a new ValidationError
-func NewValidationError(testType, message string) *ValidationError {
+func NewValidationError(testType string, code int, message string) *ValidationError {
 	return &ValidationError{
-		TestError: NewTestError(testType, message, nil),
+		TestError: NewTestError(testType, code, message, nil),
 	}
 }
 
@@ -73,8 +115,45 @@ type ParseError struct {
 }
 
 // NewParseError creates a new ParseError
-func NewParseError(testType, message string, err error) *ParseError {
+func NewParseError(testType string, code int, message string, err error) *ParseError {
 	return &ParseError{
-		TestError: NewTestError(testType, message, err),
+		TestError: NewTestError(testType, code, message, err),
+	}
+}
+
+// ClassifyError maps an error to one of the ErrCode* constants.
+// Errors carrying their own code (such as *TestError and its wrappers) keep it.
+func ClassifyError(err error) int {
+	if err == nil {
+		return ErrCodeNone
+	}
+
+	var coded interface{ Code() int }
+	if errors.As(err, &coded) {
+		return coded.Code()
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return ErrCodeDNS
 	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return ErrCodeTimeout
+	}
+
+	var (
+		recordErr    tls.RecordHeaderError
+		authorityErr x509.UnknownAuthorityError
+		certErr      x509.CertificateInvalidError
+		hostnameErr  x509.HostnameError
+	)
+	if errors.As(err, &recordErr) || errors.As(err, &authorityErr) ||
+		errors.As(err, &certErr) || errors.As(err, &hostnameErr) ||
+		strings.Contains(err.Error(), "tls: ") {
+		return ErrCodeTLS
+	}
+
+	return ErrCodeNetwork
 }
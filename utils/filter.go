@@ -0,0 +1,154 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+)
+
+// Test types passed to a FilterFunc
+const (
+	TestTypeHTTP     = "http"
+	TestTypeSpeed    = "speed"
+	TestTypeDNS      = "dns"
+	TestTypePing     = "ping"
+	TestTypeVPN      = "vpn"
+	TestTypeCertPin  = "cert_pin"
+	TestTypePortScan = "port_scan"
+)
+
+// Output filters selecting the tests written to the results file
+const (
+	OutputFilterAll        = "all"
+	OutputFilterFailedOnly = "failed-only"
+	OutputFilterSlow       = "slow"
+)
+
+// FilterFunc decides whether a single test is kept by TestResultsFilter.
+// url is the tested URL, domain or host (empty for the VPN test), err is the test's error
+// and latency is its most representative duration: latency for HTTP tests, elapsed time for
+// speed tests, resolution time for DNS tests, average RTT for ping tests and scan duration for port scans.
+type FilterFunc func(testType string, url string, err string, latency time.Duration) bool
+
+// TestResultsFilter returns a new TestResults holding only the tests for which filter returns true.
+// Single tests such as the VPN and ping tests are reset to their zero value when they do not match.
+// Derived data (Summary, HTTPStats and SpeedStats) is not carried over because it would no longer
+// describe the remaining tests.
+func TestResultsFilter(results *TestResults, filter FilterFunc) *TestResults {
+	filtered := &TestResults{
+		Timestamp: results.Timestamp,
+	}
+
+	for _, t := range results.HTTPTests {
+		if filter(TestTypeHTTP, t.URL, t.Error, t.Latency) {
+			filtered.HTTPTests = append(filtered.HTTPTests, t)
+		}
+	}
+
+	for _, t := range results.SpeedTests {
+		if filter(TestTypeSpeed, t.URL, t.Error, t.ElapsedTime) {
+			filtered.SpeedTests = append(filtered.SpeedTests, t)
+		}
+	}
+
+	for _, t := range results.DNSTests {
+		if filter(TestTypeDNS, t.Domain, t.Error, t.ResolutionTime) {
+			filtered.DNSTests = append(filtered.DNSTests, t)
+		}
+	}
+
+	for _, t := range results.CertPinTests {
+		if filter(TestTypeCertPin, t.URL, t.Error, 0) {
+			filtered.CertPinTests = append(filtered.CertPinTests, t)
+		}
+	}
+
+	if p := results.PingTest; p.URL != "" || p.Error != "" {
+		avgRTT := time.Duration(p.AvgRTTMS * float64(time.Millisecond))
+		if filter(TestTypePing, p.URL, p.Error, avgRTT) {
+			filtered.PingTest = p
+		}
+	}
+
+	if v := results.VPNTest; v.Status != "" || v.Error != "" {
+		if filter(TestTypeVPN, "", v.Error, 0) {
+			filtered.VPNTest = v
+		}
+	}
+
+	if s := results.PortScanTest; s != nil && filter(TestTypePortScan, s.Host, s.Error, s.ScanDuration) {
+		filtered.PortScanTest = s
+	}
+
+	return filtered
+}
+
+// FailedOnly is a FilterFunc keeping tests that reported an error
+func FailedOnly(testType string, url string, err string, latency time.Duration) bool {
+	return err != ""
+}
+
+// SlowerThan returns a FilterFunc keeping tests whose latency exceeds threshold
+func SlowerThan(threshold time.Duration) FilterFunc {
+	return func(testType string, url string, err string, latency time.Duration) bool {
+		return latency > threshold
+	}
+}
+
+// ForURL returns a FilterFunc keeping tests of the given URL, domain or host
+func ForURL(target string) FilterFunc {
+	return func(testType string, url string, err string, latency time.Duration) bool {
+		return url == target
+	}
+}
+
+// FilterResults applies an output filter to r. "failed-only" keeps tests that reported an error
+// and "slow" keeps tests whose latency exceeds slowThreshold; "all" and "" return r unchanged.
+func FilterResults(r *TestResults, filter string, slowThreshold time.Duration) *TestResults {
+	switch filter {
+	case OutputFilterFailedOnly:
+		return TestResultsFilter(r, FailedOnly)
+	case OutputFilterSlow:
+		return TestResultsFilter(r, SlowerThan(slowThreshold))
+	}
+	return r
+}
+
+// ValidateOutputFilter checks that filter is a known output filter
+func ValidateOutputFilter(filter string) error {
+	switch filter {
+	case "", OutputFilterAll, OutputFilterFailedOnly, OutputFilterSlow:
+		return nil
+	}
+	return NewValidationError("Config", ErrCodeValidation,
+		fmt.Sprintf("unsupported output filter %q (want all, failed-only or slow)", filter))
+}
+
+// GroupByURL splits the HTTP, speed and ping tests of r by URL.
+// Each group holds only the tests of its URL and keeps the Timestamp of r.
+func (r *TestResults) GroupByURL() map[string]*TestResults {
+	groups := make(map[string]*TestResults)
+	group := func(url string) *TestResults {
+		g, ok := groups[url]
+		if !ok {
+			g = &TestResults{Timestamp: r.Timestamp}
+			groups[url] = g
+		}
+		return g
+	}
+
+	for _, t := range r.HTTPTests {
+		g := group(t.URL)
+		g.HTTPTests = append(g.HTTPTests, t)
+	}
+
+	for _, t := range r.SpeedTests {
+		g := group(t.URL)
+		g.SpeedTests = append(g.SpeedTests, t)
+	}
+
+	if p := r.PingTest; p.URL != "" {
+		group(p.URL).PingTest = p
+	}
+
+	return groups
+}
@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+)
+
+// grafanaTimeout bounds each request to the Grafana annotations API
+const grafanaTimeout = 10 * time.Second
+
+// GrafanaAnnotation is an event for the Grafana annotations API. Times are Unix milliseconds.
+type GrafanaAnnotation struct {
+	DashboardUID string   `json:"dashboardUID,omitempty"`
+	Time         int64    `json:"time"`
+	TimeEnd      int64    `json:"timeEnd,omitempty"`
+	Tags         []string `json:"tags"`
+	Text         string   `json:"text"`
+}
+
+// ToGrafanaAnnotations returns one annotation per failed HTTP, DNS, speed, VPN and ping test,
+// tagged with "internet-test", the kind of test and "error". An annotation starts at the run
+// timestamp and, when the test recorded its duration, ends when the test did.
+func (r *TestResults) ToGrafanaAnnotations() []GrafanaAnnotation {
+	runAt := r.Timestamp
+	if runAt.IsZero() {
+		runAt = time.Now()
+	}
+
+	var annotations []GrafanaAnnotation
+	add := func(kind, text string, duration time.Duration) {
+		annotations = append(annotations, GrafanaAnnotation{
+			Time:    runAt.UnixMilli(),
+			TimeEnd: runAt.Add(duration).UnixMilli(),
+			Tags:    []string{"internet-test", kind, "error"},
+			Text:    text,
+		})
+	}
+
+	for _, t := range r.HTTPTests {
+		if t.Error != "" {
+			add("http", "HTTP test of "+t.URL+" failed: "+t.Error, t.TotalTime)
+		}
+	}
+	for _, t := range r.DNSTests {
+		if t.Error != "" {
+			add("dns", "DNS lookup of "+t.Domain+" failed: "+t.Error, t.ResolutionTime)
+		}
+	}
+	for _, t := range r.SpeedTests {
+		if t.Error != "" {
+			add("speed", "Speed test of "+t.URL+" failed: "+t.Error, t.ElapsedTime)
+		}
+	}
+	if r.VPNTest.Error != "" {
+		add("vpn", "VPN test failed: "+r.VPNTest.Error, 0)
+	}
+	if r.PingTest.Error != "" {
+		add("ping", "Ping of "+r.PingTest.URL+" failed: "+r.PingTest.Error, 0)
+	}
+
+	return annotations
+}
+
+// PostGrafanaAnnotations posts the annotations of the failed tests in results to the Grafana
+// instance at cfg.GrafanaURL, authenticated with the API key or service account token in
+// cfg.GrafanaAPIKey. Annotations are attached to cfg.GrafanaDashboardUID when set and are
+// organization wide otherwise. Nothing is sent when no test failed.
+func PostGrafanaAnnotations(cfg *config.Config, results *TestResults) error {
+	if cfg.GrafanaURL == "" {
+		return NewValidationError("Grafana", ErrCodeValidation, "Grafana URL is required")
+	}
+
+	endpoint := strings.TrimSuffix(cfg.GrafanaURL, "/") + "/api/annotations"
+	client := &http.Client{Timeout: grafanaTimeout}
+
+	for _, annotation := range results.ToGrafanaAnnotations() {
+		annotation.DashboardUID = cfg.GrafanaDashboardUID
+
+		body, err := json.Marshal(annotation)
+		if err != nil {
+			return NewParseError("Grafana", ErrCodeParse, "failed to encode annotation", err)
+		}
+
+		req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return NewValidationError("Grafana", ErrCodeValidation, "invalid Grafana URL: "+err.Error())
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if cfg.GrafanaAPIKey != "" {
+			req.Header.Set("Authorization", "Bearer "+cfg.GrafanaAPIKey)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return NewNetworkError("Grafana", ErrCodeNetwork, "failed to post annotation", err)
+		}
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+
+		if resp.StatusCode/100 != 2 {
+			return NewNetworkError("Grafana", ErrCodeHTTP,
+				fmt.Sprintf("Grafana returned %s: %s", resp.Status, strings.TrimSpace(string(message))), nil)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,214 @@
+// Command schemagen writes the JSON Schema of utils.TestResults.
+// It reads the type declarations and doc comments of the utils package source, so the schema
+// follows the structs and their comments. Run it through go generate in the utils directory.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// schema is a JSON Schema node
+type schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Properties           map[string]*schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *schema            `json:"items,omitempty"`
+	AdditionalProperties *schema            `json:"additionalProperties,omitempty"`
+	Definitions          map[string]*schema `json:"definitions,omitempty"`
+}
+
+// generator converts the struct types of a package into schema definitions
+type generator struct {
+	types       map[string]*ast.TypeSpec
+	docs        map[string]string
+	definitions map[string]*schema
+}
+
+func main() {
+	dir := flag.String("dir", ".", "directory of the utils package")
+	root := flag.String("type", "TestResults", "root type of the schema")
+	out := flag.String("o", "schema.json", "output file")
+	flag.Parse()
+
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, *dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	g := &generator{
+		types:       make(map[string]*ast.TypeSpec),
+		docs:        make(map[string]string),
+		definitions: make(map[string]*schema),
+	}
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					ts := spec.(*ast.TypeSpec)
+					g.types[ts.Name.Name] = ts
+					doc := ts.Doc
+					if doc == nil {
+						doc = gen.Doc
+					}
+					g.docs[ts.Name.Name] = comment(doc)
+				}
+			}
+		}
+	}
+
+	if _, ok := g.types[*root]; !ok {
+		log.Fatalf("type %s not found in %s", *root, *dir)
+	}
+
+	s := g.define(*root)
+	s.Schema = "http://json-schema.org/draft-07/schema#"
+	s.Title = *root
+	s.Definitions = g.definitions
+	delete(g.definitions, *root)
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, append(data, '\n'), 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// define builds the schema of the named struct type and records it as a definition
+func (g *generator) define(name string) *schema {
+	if s, ok := g.definitions[name]; ok {
+		return s
+	}
+
+	st, ok := g.types[name].Type.(*ast.StructType)
+	if !ok {
+		log.Fatalf("type %s is not a struct", name)
+	}
+
+	s := &schema{
+		Description: g.docs[name],
+		Type:        "object",
+		Properties:  make(map[string]*schema),
+	}
+	g.definitions[name] = s
+
+	for _, field := range st.Fields.List {
+		if len(field.Names) == 0 {
+			log.Fatalf("embedded field in %s is not supported", name)
+		}
+		tag := ""
+		if field.Tag != nil {
+			tag = reflect.StructTag(strings.Trim(field.Tag.Value, "`")).Get("json")
+		}
+		jsonName, opts, _ := strings.Cut(tag, ",")
+		if jsonName == "-" {
+			continue
+		}
+
+		for _, ident := range field.Names {
+			if !ident.IsExported() {
+				continue
+			}
+			key := jsonName
+			if key == "" {
+				key = ident.Name
+			}
+
+			// Validators ignore keywords next to $ref, but the description still documents the field
+			prop := g.typeSchema(field.Type)
+			prop.Description = comment(field.Doc)
+			if prop.Description == "" {
+				prop.Description = comment(field.Comment)
+			}
+			s.Properties[key] = prop
+
+			if !strings.Contains(opts, "omitempty") {
+				s.Required = append(s.Required, key)
+			}
+		}
+	}
+	sort.Strings(s.Required)
+
+	return s
+}
+
+// typeSchema returns the schema of a field type
+func (g *generator) typeSchema(expr ast.Expr) *schema {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return g.typeSchema(t.X)
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok && ident.Name == "byte" {
+			return &schema{Type: "string", Format: "byte"}
+		}
+		return &schema{Type: "array", Items: g.typeSchema(t.Elt)}
+	case *ast.MapType:
+		return &schema{Type: "object", AdditionalProperties: g.typeSchema(t.Value)}
+	case *ast.SelectorExpr:
+		switch fmt.Sprintf("%s.%s", t.X, t.Sel.Name) {
+		case "time.Time":
+			return &schema{Type: "string", Format: "date-time"}
+		case "time.Duration":
+			return &schema{Type: "integer"}
+		case "net.IP":
+			return &schema{Type: "string"}
+		}
+		log.Fatalf("unsupported type %s.%s", t.X, t.Sel.Name)
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			return &schema{Type: "string"}
+		case "bool":
+			return &schema{Type: "boolean"}
+		case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+			return &schema{Type: "integer"}
+		case "float32", "float64":
+			return &schema{Type: "number"}
+		case "interface{}", "any":
+			return &schema{}
+		}
+		if _, ok := g.types[t.Name]; ok {
+			if _, isStruct := g.types[t.Name].Type.(*ast.StructType); isStruct {
+				g.define(t.Name)
+				return &schema{Ref: "#/definitions/" + t.Name}
+			}
+			return g.typeSchema(g.types[t.Name].Type)
+		}
+		log.Fatalf("unknown type %s", t.Name)
+	case *ast.InterfaceType:
+		return &schema{}
+	}
+	log.Fatalf("unsupported type expression %T", expr)
+	return nil
+}
+
+// comment returns the text of a comment group joined into one line
+func comment(cg *ast.CommentGroup) string {
+	if cg == nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(cg.Text()), " ")
+}
@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ParseTestResultsFromMultipleFiles loads the results files at paths with LoadResults.
+// Each path may also be a glob pattern such as "agents/*.json". Files that cannot be loaded
+// are skipped and their errors joined into the returned error, so callers get every result
+// that could be parsed even when the error is non-nil.
+func ParseTestResultsFromMultipleFiles(paths []string) ([]*TestResults, error) {
+	var files []string
+	var errs []error
+
+	for _, path := range paths {
+		if !strings.ContainsAny(path, "*?[") {
+			files = append(files, path)
+			continue
+		}
+
+		matches, err := filepath.Glob(path)
+		if err != nil {
+			errs = append(errs, NewValidationError("Storage", ErrCodeValidation, fmt.Sprintf("invalid glob pattern %q: %v", path, err)))
+			continue
+		}
+		if len(matches) == 0 {
+			errs = append(errs, NewValidationError("Storage", ErrCodeValidation, fmt.Sprintf("no files match %q", path)))
+		}
+		files = append(files, matches...)
+	}
+
+	var results []*TestResults
+	for _, file := range files {
+		// LoadResults treats a missing file as empty results, which would hide a wrong path here
+		if _, err := os.Stat(file); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", file, NewNetworkError("Storage", ErrCodeNetwork, "failed to read results file", err)))
+			continue
+		}
+
+		r, err := LoadResults(file)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", file, err))
+			continue
+		}
+		results = append(results, r)
+	}
+
+	return results, errors.Join(errs...)
+}
+
+// MergeResults combines several test runs into one. The tests of all runs are concatenated in
+// order, while single tests (VPN, ping and port scan) and the timestamp come from the most recent
+// run that has them. The summary is recomputed for the merged tests.
+func MergeResults(runs []*TestResults) *TestResults {
+	merged := &TestResults{}
+
+	for _, r := range runs {
+		merged.HTTPTests = append(merged.HTTPTests, r.HTTPTests...)
+		merged.SpeedTests = append(merged.SpeedTests, r.SpeedTests...)
+		merged.CertPinTests = append(merged.CertPinTests, r.CertPinTests...)
+		merged.DNSTests = append(merged.DNSTests, r.DNSTests...)
+		merged.HTTPStats = append(merged.HTTPStats, r.HTTPStats...)
+		merged.SpeedStats = append(merged.SpeedStats, r.SpeedStats...)
+
+		newer := !r.Timestamp.Before(merged.Timestamp)
+		if newer {
+			merged.Timestamp = r.Timestamp
+		}
+		if (r.VPNTest.Status != "" || r.VPNTest.Error != "") && (newer || merged.VPNTest.Status == "" && merged.VPNTest.Error == "") {
+			merged.VPNTest = r.VPNTest
+		}
+		if r.PingTest.URL != "" && (newer || merged.PingTest.URL == "") {
+			merged.PingTest = r.PingTest
+		}
+		if r.PortScanTest != nil && (newer || merged.PortScanTest == nil) {
+			merged.PortScanTest = r.PortScanTest
+		}
+	}
+
+	merged.Summary = &Summary{
+		FailedTests: len(FailedTests(merged)),
+	}
+	if len(merged.SpeedTests) > 0 {
+		merged.Summary.SpeedAggregate = AggregateSpeedTests(merged.SpeedTests)
+	}
+
+	return merged
+}
@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+// MockResponse configures how a MockHTTPServer answers requests for one path
+type MockResponse struct {
+	Path    string
+	Status  int
+	Headers map[string]string
+	Body    string
+	Delay   time.Duration
+}
+
+// NewMockHTTPServer starts a local HTTP server answering each configured path with its MockResponse.
+// Requests for other paths receive 404 Not Found. The caller must Close the server.
+//
+// Example:
+//
+//	server := NewMockHTTPServer([]MockResponse{{Path: "/", Status: http.StatusOK, Body: "hello"}})
+//	defer server.Close()
+//	result := modules.TestHTTP(server.URL+"/", config.New())
+func NewMockHTTPServer(responses []MockResponse) *httptest.Server {
+	mux := http.NewServeMux()
+
+	for _, r := range responses {
+		response := r
+		mux.HandleFunc(response.Path, func(w http.ResponseWriter, req *http.Request) {
+			// Only answer the exact path, not the subtree registered by a trailing slash
+			if req.URL.Path != response.Path {
+				http.NotFound(w, req)
+				return
+			}
+
+			if response.Delay > 0 {
+				select {
+				case <-time.After(response.Delay):
+				case <-req.Context().Done():
+					return
+				}
+			}
+
+			for k, v := range response.Headers {
+				w.Header().Set(k, v)
+			}
+
+			status := response.Status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			w.WriteHeader(status)
+			w.Write([]byte(response.Body))
+		})
+	}
+
+	return httptest.NewServer(mux)
+}
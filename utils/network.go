@@ -0,0 +1,180 @@
+package utils
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ResolveInterfaceAddr returns the first non-loopback address assigned to the named network interface,
+// suitable for use as a net.Dialer LocalAddr
+func ResolveInterfaceAddr(iface string) (net.Addr, error) {
+	ifi, err := net.InterfaceByName(iface)
+	if err != nil {
+		return nil, NewValidationError("Config", ErrCodeValidation, fmt.Sprintf("network interface %q not found", iface))
+	}
+
+	addrs, err := ifi.Addrs()
+	if err != nil {
+		return nil, NewValidationError("Config", ErrCodeValidation, fmt.Sprintf("cannot list addresses of interface %q: %v", iface, err))
+	}
+
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLoopback() || ipNet.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		return &net.TCPAddr{IP: ipNet.IP}, nil
+	}
+
+	return nil, NewValidationError("Config", ErrCodeValidation, fmt.Sprintf("network interface %q has no usable IP address", iface))
+}
+
+// HTTP versions that can be forced with config.Config.HTTPVersion
+const (
+	HTTPVersion11 = "1.1"
+	HTTPVersion2  = "2"
+	HTTPVersion3  = "3"
+)
+
+// ValidateHTTPVersion checks that version is empty or one of the HTTP versions that can be forced
+func ValidateHTTPVersion(version string) error {
+	switch version {
+	case "", HTTPVersion11, HTTPVersion2, HTTPVersion3:
+		return nil
+	}
+	return NewValidationError("Config", ErrCodeValidation,
+		fmt.Sprintf("unsupported HTTP version %q (want 1.1, 2 or 3)", version))
+}
+
+// ValidateLocalAddr checks that addr is an IP address assigned to one of the local network interfaces
+func ValidateLocalAddr(addr string) error {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return NewValidationError("Config", ErrCodeValidation, fmt.Sprintf("invalid IP address %q", addr))
+	}
+
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return NewValidationError("Config", ErrCodeValidation, fmt.Sprintf("cannot list network interfaces: %v", err))
+	}
+
+	for _, ifi := range ifaces {
+		addrs, err := ifi.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, a := range addrs {
+			if ipNet, ok := a.(*net.IPNet); ok && ipNet.IP.Equal(ip) {
+				return nil
+			}
+		}
+	}
+
+	return NewValidationError("Config", ErrCodeValidation, fmt.Sprintf("IP address %s is not assigned to a local interface", addr))
+}
+
+// ParsePorts parses a comma separated list of ports and port ranges (e.g., "22,80,8000-8010")
+func ParsePorts(spec string) ([]int, error) {
+	var ports []int
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		low, high, isRange := strings.Cut(part, "-")
+		if !isRange {
+			high = low
+		}
+
+		first, err := parsePort(low)
+		if err != nil {
+			return nil, err
+		}
+		last, err := parsePort(high)
+		if err != nil {
+			return nil, err
+		}
+		if first > last {
+			return nil, NewValidationError("Config", ErrCodeValidation, fmt.Sprintf("invalid port range %q", part))
+		}
+
+		for p := first; p <= last; p++ {
+			ports = append(ports, p)
+		}
+	}
+
+	if len(ports) == 0 {
+		return nil, NewValidationError("Config", ErrCodeValidation, "no ports specified")
+	}
+
+	return ports, nil
+}
+
+// parsePort parses a single TCP/UDP port number
+func parsePort(s string) (int, error) {
+	port, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || port < 1 || port > 65535 {
+		return 0, NewValidationError("Config", ErrCodeValidation, fmt.Sprintf("invalid port %q", s))
+	}
+	return port, nil
+}
+
+// URLOptions is a test URL together with the per-URL options parsed by ParseURLWithTimeout
+type URLOptions struct {
+	URL string
+
+	// Interface or SourceIP selects the local address connections are made from
+	Interface string
+	SourceIP  net.IP
+
+	// Timeout overrides the HTTP timeout when non-zero
+	Timeout time.Duration
+}
+
+// ParseURLWithTimeout parses a test URL with optional per-URL options appended after the last "@":
+//
+//	https://example.com@eth0:5s      bind to interface eth0, 5 second timeout
+//	https://example.com@192.0.2.7    use source IP 192.0.2.7
+//	https://example.com@2001:db8::7:3s
+//
+// The suffix is only treated as options when it names a source IP or an existing network
+// interface, so user info such as "https://user@example.com" is left untouched.
+func ParseURLWithTimeout(raw string) (*URLOptions, error) {
+	opts := &URLOptions{URL: raw}
+
+	at := strings.LastIndex(raw, "@")
+	if at < 0 || strings.ContainsAny(raw[at+1:], "/?#") {
+		return opts, nil
+	}
+
+	source, timeoutSpec := raw[at+1:], ""
+	if colon := strings.LastIndex(source, ":"); colon >= 0 {
+		if _, err := time.ParseDuration(source[colon+1:]); err == nil {
+			source, timeoutSpec = source[:colon], source[colon+1:]
+		}
+	}
+
+	if ip := net.ParseIP(source); ip != nil {
+		opts.SourceIP = ip
+	} else if _, err := net.InterfaceByName(source); err == nil {
+		opts.Interface = source
+	} else {
+		return opts, nil
+	}
+
+	if timeoutSpec != "" {
+		timeout, _ := time.ParseDuration(timeoutSpec)
+		if timeout <= 0 {
+			return nil, NewValidationError("Config", ErrCodeValidation, fmt.Sprintf("invalid timeout %q in %q", timeoutSpec, raw))
+		}
+		opts.Timeout = timeout
+	}
+
+	opts.URL = raw[:at]
+	return opts, nil
+}
@@ -0,0 +1,75 @@
+package utils
+
+import "time"
+
+// Perceived quality labels returned by RateDownloadQuality and RatePingQuality
+const (
+	QualityExcellent = "Excellent"
+	QualityGood      = "Good"
+	QualityFair      = "Fair"
+	QualityPoor      = "Poor"
+	QualityVeryPoor  = "Very Poor"
+	QualityNoData    = "No data"
+)
+
+// RateDownloadQuality labels a download speed the way a user would perceive it, in the spirit of a
+// mean opinion score: above 100 Mbps is Excellent, 25-100 Good, 10-25 Fair, 5-10 Poor and below 5 Very Poor.
+// A speed of 0 means nothing was measured.
+func RateDownloadQuality(mbps float64) string {
+	switch {
+	case mbps <= 0:
+		return QualityNoData
+	case mbps > 100:
+		return QualityExcellent
+	case mbps >= 25:
+		return QualityGood
+	case mbps >= 10:
+		return QualityFair
+	case mbps >= 5:
+		return QualityPoor
+	}
+	return QualityVeryPoor
+}
+
+// RatePingQuality labels a ping result by its average round trip time and packet loss percentage.
+// The worse of both decides: Excellent needs under 30ms and under 0.5% loss, Good under 80ms and 1%,
+// Fair under 150ms and 3%, Poor under 300ms and 10%. Anything worse, including total loss, is Very Poor.
+func RatePingQuality(avgRTT time.Duration, loss float64) string {
+	if avgRTT <= 0 && loss <= 0 {
+		return QualityNoData
+	}
+	if loss >= 100 {
+		return QualityVeryPoor
+	}
+
+	switch {
+	case avgRTT < 30*time.Millisecond && loss < 0.5:
+		return QualityExcellent
+	case avgRTT < 80*time.Millisecond && loss < 1:
+		return QualityGood
+	case avgRTT < 150*time.Millisecond && loss < 3:
+		return QualityFair
+	case avgRTT < 300*time.Millisecond && loss < 10:
+		return QualityPoor
+	}
+	return QualityVeryPoor
+}
+
+// Bufferbloat severities returned by RateBufferbloat
+const (
+	BufferbloatNone   = "none"
+	BufferbloatMild   = "mild"
+	BufferbloatSevere = "severe"
+)
+
+// RateBufferbloat labels how much latency grows under load: an increase below 30ms is none,
+// below 100ms mild and anything more severe
+func RateBufferbloat(increase time.Duration) string {
+	switch {
+	case increase < 30*time.Millisecond:
+		return BufferbloatNone
+	case increase < 100*time.Millisecond:
+		return BufferbloatMild
+	}
+	return BufferbloatSevere
+}
@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// RateLimiter is a token bucket that limits how many requests may start per second
+type RateLimiter struct {
+	tokens chan struct{}
+	ticker *time.Ticker
+	done   chan struct{}
+}
+
+// NewRateLimiter creates a token bucket refilled at requestsPerSecond.
+// A nil limiter is returned when requestsPerSecond is not positive, meaning unlimited.
+func NewRateLimiter(requestsPerSecond float64) *RateLimiter {
+	if requestsPerSecond <= 0 {
+		return nil
+	}
+
+	// Allow bursts of up to one second worth of requests
+	burst := int(math.Ceil(requestsPerSecond))
+
+	rl := &RateLimiter{
+		tokens: make(chan struct{}, burst),
+		ticker: time.NewTicker(time.Duration(float64(time.Second) / requestsPerSecond)),
+		done:   make(chan struct{}),
+	}
+
+	// Start with a single token so the first request is not delayed, while the ticks that follow
+	// keep the first second at requestsPerSecond instead of a full burst on top of them
+	rl.tokens <- struct{}{}
+
+	go rl.refill()
+
+	return rl
+}
+
+// refill adds a token on every tick, dropping it when the bucket is full
+func (rl *RateLimiter) refill() {
+	for {
+		select {
+		case <-rl.ticker.C:
+			select {
+			case rl.tokens <- struct{}{}:
+			default:
+			}
+		case <-rl.done:
+			return
+		}
+	}
+}
+
+// Wait blocks until a token is available or ctx is done, returning ctx's error in the latter case.
+// It returns immediately on a nil limiter.
+func (rl *RateLimiter) Wait(ctx context.Context) error {
+	if rl == nil {
+		return nil
+	}
+	select {
+	case <-rl.tokens:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stop releases the limiter's ticker
+func (rl *RateLimiter) Stop() {
+	if rl == nil {
+		return
+	}
+	rl.ticker.Stop()
+	close(rl.done)
+}
@@ -0,0 +1,20 @@
+package utils
+
+import (
+	_ "embed"
+)
+
+//go:generate go run ./internal/schemagen -type TestResults -o schema.json
+
+// testResultsSchema is generated from the struct declarations and doc comments in this package
+//
+//go:embed schema.json
+var testResultsSchema []byte
+
+// TestResultsSchema returns the JSON Schema (draft 7) describing the JSON encoding of TestResults.
+// Run go generate in this package after changing any result struct to keep it in sync.
+func TestResultsSchema() []byte {
+	schema := make([]byte, len(testResultsSchema))
+	copy(schema, testResultsSchema)
+	return schema
+}
@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseServerTiming parses the value of one or more Server-Timing headers.
+// Each comma separated metric is a name followed by optional ";param=value" pairs, where the value
+// is a token or a quoted string. The "dur" parameter is in milliseconds and "desc" is a description;
+// other parameters are ignored. It returns nil when the header holds no metrics.
+//
+// Example:
+//
+//	info := ParseServerTiming(`cache;desc="Cache Read";dur=23.2, db;dur=53, app`)
+//	// info.Metrics[0] = {Name: "cache", Description: "Cache Read", Duration: 23.2ms}
+func ParseServerTiming(header string) *ServerTimingInfo {
+	var info ServerTimingInfo
+
+	for _, entry := range splitQuoted(header, ',') {
+		params := splitQuoted(entry, ';')
+
+		name := strings.TrimSpace(params[0])
+		if name == "" {
+			continue
+		}
+		metric := ServerTimingMetric{Name: name}
+
+		for _, param := range params[1:] {
+			key, value, _ := strings.Cut(param, "=")
+			value = unquote(strings.TrimSpace(value))
+
+			// Only the first occurrence of a parameter counts
+			switch strings.ToLower(strings.TrimSpace(key)) {
+			case "dur":
+				if ms, err := strconv.ParseFloat(value, 64); err == nil && metric.Duration == 0 {
+					metric.Duration = time.Duration(ms * float64(time.Millisecond))
+				}
+			case "desc":
+				if metric.Description == "" {
+					metric.Description = value
+				}
+			}
+		}
+
+		info.Metrics = append(info.Metrics, metric)
+	}
+
+	if len(info.Metrics) == 0 {
+		return nil
+	}
+	return &info
+}
+
+// splitQuoted splits s at every sep that is not inside a quoted string
+func splitQuoted(s string, sep byte) []string {
+	var parts []string
+	inQuotes, escaped := false, false
+	start := 0
+
+	for i := 0; i < len(s); i++ {
+		switch {
+		case escaped:
+			escaped = false
+		case inQuotes && s[i] == '\\':
+			escaped = true
+		case s[i] == '"':
+			inQuotes = !inQuotes
+		case !inQuotes && s[i] == sep:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, s[start:])
+}
+
+// unquote removes the quotes and backslash escapes of an HTTP quoted string.
+// Values that are not quoted are returned unchanged.
+func unquote(s string) string {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return s
+	}
+
+	var b strings.Builder
+	s = s[1 : len(s)-1]
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+)
+
+// SLAReport represents the uptime compliance of a single URL over a series of test runs
+type SLAReport struct {
+	URL         string  `json:"url"`
+	TargetPct   float64 `json:"target_pct"`
+	ActualPct   float64 `json:"actual_pct"`
+	Compliant   bool    `json:"compliant"`
+	OutageCount int     `json:"outage_count"`
+	TotalRuns   int     `json:"total_runs"`
+}
+
+// GenerateSLAReport computes the uptime of every HTTP tested URL in history and compares it against target.
+// A run counts as up when the HTTP test for the URL has no error. Reports are sorted by URL.
+func GenerateSLAReport(history []TestResults, target config.SLATarget) []SLAReport {
+	byURL := make(map[string]*SLAReport)
+
+	for _, run := range history {
+		for _, test := range run.HTTPTests {
+			report, ok := byURL[test.URL]
+			if !ok {
+				report = &SLAReport{
+					URL:       test.URL,
+					TargetPct: target.UptimePct,
+				}
+				byURL[test.URL] = report
+			}
+
+			report.TotalRuns++
+			if test.Error != "" {
+				report.OutageCount++
+			}
+		}
+	}
+
+	reports := make([]SLAReport, 0, len(byURL))
+	for _, report := range byURL {
+		report.ActualPct = float64(report.TotalRuns-report.OutageCount) / float64(report.TotalRuns) * 100
+		report.Compliant = report.ActualPct >= report.TargetPct
+		reports = append(reports, *report)
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].URL < reports[j].URL
+	})
+
+	return reports
+}
+
+// SaveSLAReport writes SLA reports to a file, as CSV when the path ends in ".csv" and as JSON otherwise
+func SaveSLAReport(reports []SLAReport, filePath string, filePermissions os.FileMode) error {
+	var (
+		data []byte
+		err  error
+	)
+
+	if strings.EqualFold(filepath.Ext(filePath), ".csv") {
+		data, err = marshalSLAReportCSV(reports)
+	} else {
+		data, err = json.MarshalIndent(reports, "", "  ")
+	}
+	if err != nil {
+		return NewParseError("SLA", ErrCodeParse, "failed to marshal SLA report", err)
+	}
+
+	if err := os.WriteFile(filePath, data, filePermissions); err != nil {
+		return NewNetworkError("SLA", ErrCodeNetwork, "failed to write SLA report file", err)
+	}
+
+	return nil
+}
+
+// marshalSLAReportCSV encodes SLA reports as CSV with a header row
+func marshalSLAReportCSV(reports []SLAReport) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	if err := w.Write([]string{"url", "target_pct", "actual_pct", "compliant", "outage_count", "total_runs"}); err != nil {
+		return nil, err
+	}
+
+	for _, r := range reports {
+		record := []string{
+			r.URL,
+			strconv.FormatFloat(r.TargetPct, 'f', 3, 64),
+			strconv.FormatFloat(r.ActualPct, 'f', 3, 64),
+			strconv.FormatBool(r.Compliant),
+			strconv.Itoa(r.OutageCount),
+			strconv.Itoa(r.TotalRuns),
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
@@ -1,8 +1,14 @@
 package utils
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
 	"encoding/json"
+	"fmt"
+	"io"
 	"os"
+	"strconv"
 	"sync"
 	"time"
 )
@@ -17,7 +23,7 @@ func LoadResults(filePath string) (*TestResults, error) {
 	resultsMutex.Lock()
 	defer resultsMutex.Unlock()
 
-	data, err := os.ReadFile(filePath)
+	f, err := os.Open(filePath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			// File doesn't exist yet, return empty results
@@ -25,26 +31,206 @@ func LoadResults(filePath string) (*TestResults, error) {
 				Timestamp: time.Now(),
 			}, nil
 		}
-		return nil, NewNetworkError("Storage", "failed to read results file", err)
+		return nil, NewNetworkError("Storage", ErrCodeNetwork, "failed to read results file", err)
+	}
+	defer f.Close()
+
+	// Transparently decompress gzip files regardless of their extension
+	r := bufio.NewReader(f)
+	if magic, err := r.Peek(len(gzipMagic)); err == nil && isGzip(magic) {
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, NewParseError("Storage", ErrCodeParse, "failed to decompress results file", err)
+		}
+		defer zr.Close()
+		return NewTestResultsFromReader(zr)
+	}
+
+	return NewTestResultsFromReader(r)
+}
+
+// NewTestResultsFromReader decodes test results from a JSON stream and validates them like LoadResults
+func NewTestResultsFromReader(r io.Reader) (*TestResults, error) {
+	if r == nil {
+		return nil, NewValidationError("Storage", ErrCodeValidation, "reader cannot be nil")
 	}
 
 	var results TestResults
-	if err := json.Unmarshal(data, &results); err != nil {
-		return nil, NewParseError("Storage", "failed to parse results JSON", err)
+	if err := json.NewDecoder(r).Decode(&results); err != nil {
+		return nil, NewParseError("Storage", ErrCodeParse, "failed to parse results JSON", err)
+	}
+
+	if err := results.Validate(); err != nil {
+		return nil, err
 	}
 
 	return &results, nil
 }
 
-// SaveResults saves test results to a JSON file
+// LoadResultsHistory loads a series of test runs from a JSON file.
+// The file may contain either an array of results or a single results object.
+func LoadResultsHistory(filePath string) ([]TestResults, error) {
+	resultsMutex.Lock()
+	defer resultsMutex.Unlock()
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, NewNetworkError("Storage", ErrCodeNetwork, "failed to read results history file", err)
+	}
+
+	data, err = gunzipAll(data)
+	if err != nil {
+		return nil, err
+	}
+
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var history []TestResults
+		if err := json.Unmarshal(data, &history); err != nil {
+			return nil, NewParseError("Storage", ErrCodeParse, "failed to parse results history JSON", err)
+		}
+		for i := range history {
+			if err := history[i].Validate(); err != nil {
+				return nil, err
+			}
+		}
+		return history, nil
+	}
+
+	var results TestResults
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, NewParseError("Storage", ErrCodeParse, "failed to parse results JSON", err)
+	}
+
+	if err := results.Validate(); err != nil {
+		return nil, err
+	}
+
+	return []TestResults{results}, nil
+}
+
+// SaveResults saves test results to a JSON file.
+// It applies no size limit; use SaveResultsWithOptions to enforce MaxResultFileSize or TrimOnOversize.
 func SaveResults(results *TestResults, filePath string, filePermissions os.FileMode) error {
 	if results == nil {
-		return NewValidationError("Storage", "results cannot be nil")
+		return NewValidationError("Storage", ErrCodeValidation, "results cannot be nil")
 	}
 
 	resultsMutex.Lock()
 	defer resultsMutex.Unlock()
 
+	// Encode fully before touching the file so a marshalling error never truncates it
+	var data []byte
+	if isGzipPath(filePath) {
+		compressed, err := CompressResults(results)
+		if err != nil {
+			return err
+		}
+		data = compressed
+	} else {
+		var buf bytes.Buffer
+		if err := WriteResultsTo(results, &buf); err != nil {
+			return err
+		}
+		data = buf.Bytes()
+	}
+
+	if err := os.WriteFile(filePath, data, filePermissions); err != nil {
+		return NewNetworkError("Storage", ErrCodeNetwork, "failed to write results file", err)
+	}
+
+	return nil
+}
+
+// fitResults returns data, the encoding of results, when it fits maxSize bytes. Otherwise it fails
+// with a ValidationError or, with trim, drops the oldest HTTP and speed tests and re-encodes them
+// with encode until they fit. results itself is not modified. A maxSize of 0 disables the check.
+func fitResults(results *TestResults, data []byte, encode func(*TestResults) ([]byte, error), maxSize int64, trim bool) ([]byte, error) {
+	if maxSize <= 0 || int64(len(data)) <= maxSize {
+		return data, nil
+	}
+	if !trim {
+		return nil, oversizeError(len(data), maxSize)
+	}
+
+	trimmed := *results
+	for int64(len(data)) > maxSize {
+		count := len(trimmed.HTTPTests) + len(trimmed.SpeedTests)
+		if count == 0 {
+			return nil, NewValidationError("Storage", ErrCodeValidation,
+				fmt.Sprintf("results are %d bytes without any HTTP or speed tests, exceeding the %d byte limit", len(data), maxSize))
+		}
+
+		// Drop about as many tests as the excess share of the file, split between both kinds by count
+		drop := int(int64(count) * (int64(len(data)) - maxSize) / int64(len(data)))
+		if drop < 1 {
+			drop = 1
+		}
+		dropHTTP := drop * len(trimmed.HTTPTests) / count
+		dropSpeed := drop - dropHTTP
+		if dropSpeed > len(trimmed.SpeedTests) {
+			dropHTTP += dropSpeed - len(trimmed.SpeedTests)
+			dropSpeed = len(trimmed.SpeedTests)
+		}
+		trimmed.HTTPTests = trimmed.HTTPTests[dropHTTP:]
+		trimmed.SpeedTests = trimmed.SpeedTests[dropSpeed:]
+
+		var err error
+		if data, err = encode(&trimmed); err != nil {
+			return nil, err
+		}
+	}
+
+	return data, nil
+}
+
+// oversizeError reports results of size bytes that exceed the limit
+func oversizeError(size int, limit int64) error {
+	return NewValidationError("Storage", ErrCodeValidation,
+		fmt.Sprintf("results are %d bytes, exceeding the %d byte limit", size, limit))
+}
+
+// rotateResults shifts filePath to filePath.1, filePath.1 to filePath.2 and so on when its size plus
+// incoming bytes would exceed maxSize, dropping the oldest generation. Callers must hold resultsMutex.
+func rotateResults(filePath string, incoming int64, maxSize int64, generations int) error {
+	info, err := os.Stat(filePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return NewNetworkError("Storage", ErrCodeNetwork, "failed to stat results file", err)
+	}
+	if info.Size()+incoming <= maxSize {
+		return nil
+	}
+
+	if generations < 1 {
+		generations = 1
+	}
+
+	generation := func(n int) string { return filePath + "." + strconv.Itoa(n) }
+
+	if err := os.Remove(generation(generations)); err != nil && !os.IsNotExist(err) {
+		return NewNetworkError("Storage", ErrCodeNetwork, "failed to remove oldest results file", err)
+	}
+	for n := generations - 1; n >= 1; n-- {
+		if err := os.Rename(generation(n), generation(n+1)); err != nil && !os.IsNotExist(err) {
+			return NewNetworkError("Storage", ErrCodeNetwork, "failed to rotate results file", err)
+		}
+	}
+	if err := os.Rename(filePath, generation(1)); err != nil {
+		return NewNetworkError("Storage", ErrCodeNetwork, "failed to rotate results file", err)
+	}
+
+	return nil
+}
+
+// WriteResultsTo writes test results as indented JSON to w
+func WriteResultsTo(results *TestResults, w io.Writer) error {
+	if results == nil {
+		return NewValidationError("Storage", ErrCodeValidation, "results cannot be nil")
+	}
+
 	// Set timestamp if not already set
 	if results.Timestamp.IsZero() {
 		results.Timestamp = time.Now()
@@ -52,11 +238,11 @@ func SaveResults(results *TestResults, filePath string, filePermissions os.FileM
 
 	data, err := json.MarshalIndent(results, "", "  ")
 	if err != nil {
-		return NewParseError("Storage", "failed to marshal results to JSON", err)
+		return NewParseError("Storage", ErrCodeParse, "failed to marshal results to JSON", err)
 	}
 
-	if err := os.WriteFile(filePath, data, filePermissions); err != nil {
-		return NewNetworkError("Storage", "failed to write results file", err)
+	if _, err := w.Write(data); err != nil {
+		return NewNetworkError("Storage", ErrCodeNetwork, "failed to write results", err)
 	}
 
 	return nil
@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// UnmarshalTestResultsStrict decodes test results like NewTestResultsFromReader but rejects
+// fields that TestResults does not know, which usually means the data was written by a newer
+// version. The error lists every unknown field by its path (e.g., "http_tests[0].new_field").
+func UnmarshalTestResultsStrict(data []byte) (*TestResults, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var results TestResults
+	if err := decoder.Decode(&results); err != nil {
+		// The decoder stops at the first unknown field, so walk the whole document to list them all
+		var doc interface{}
+		if json.Unmarshal(data, &doc) == nil {
+			var unknown []string
+			collectUnknownFields(doc, reflect.TypeOf(results), "", &unknown)
+			if len(unknown) > 0 {
+				sort.Strings(unknown)
+				return nil, NewParseError("Storage", ErrCodeParse, "results contain unknown fields: "+strings.Join(unknown, ", "), err)
+			}
+		}
+		return nil, NewParseError("Storage", ErrCodeParse, "failed to parse results JSON", err)
+	}
+
+	return &results, nil
+}
+
+// collectUnknownFields appends the path of every object key in doc that has no matching field in t
+func collectUnknownFields(doc interface{}, t reflect.Type, path string, unknown *[]string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch value := doc.(type) {
+	case map[string]interface{}:
+		switch t.Kind() {
+		case reflect.Struct:
+			fields := jsonFields(t)
+			for key, v := range value {
+				field, ok := fields[key]
+				if !ok {
+					// encoding/json matches keys case-insensitively when there is no exact match
+					for name, f := range fields {
+						if strings.EqualFold(name, key) {
+							field, ok = f, true
+							break
+						}
+					}
+				}
+				if !ok {
+					*unknown = append(*unknown, joinPath(path, key))
+					continue
+				}
+				collectUnknownFields(v, field.Type, joinPath(path, key), unknown)
+			}
+		case reflect.Map:
+			for key, v := range value {
+				collectUnknownFields(v, t.Elem(), joinPath(path, key), unknown)
+			}
+		}
+	case []interface{}:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for i, v := range value {
+				collectUnknownFields(v, t.Elem(), path+"["+strconv.Itoa(i)+"]", unknown)
+			}
+		}
+	}
+}
+
+// jsonFields maps the JSON names of the exported fields of struct type t to their fields
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f
+	}
+	return fields
+}
+
+// joinPath appends key to a dotted field path
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
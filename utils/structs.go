@@ -9,33 +9,202 @@ type TestResults struct {
 	VPNTest    VPNTest     `json:"vpn_test,omitempty"`
 	PingTest   PingTest    `json:"ping_test,omitempty"`
 	Timestamp  time.Time   `json:"timestamp"`
+
+	CertPinTests []CertPinTest `json:"cert_pin_tests,omitempty"`
+	PortScanTest *PortScanTest `json:"port_scan_test,omitempty"`
+	DNSTests     []DNSTest     `json:"dns_tests,omitempty"`
+
+	// Summary holds statistics computed across the individual tests
+	Summary *Summary `json:"summary,omitempty"`
+
+	// HTTPStats and SpeedStats summarize repeated runs of the same test
+	HTTPStats  []HTTPTestStats  `json:"http_stats,omitempty"`
+	SpeedStats []SpeedTestStats `json:"speed_stats,omitempty"`
+
+	// Metadata labels the report
+	Metadata *Metadata `json:"metadata,omitempty"`
+}
+
+// Metadata describes a saved report and the program that generated it
+type Metadata struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	GeneratedBy string `json:"generated_by,omitempty"`
+	Version     string `json:"version,omitempty"`
+}
+
+// Summary holds aggregate statistics of a test run
+type Summary struct {
+	SpeedAggregate SpeedTestAggregate `json:"speed_aggregate"`
+
+	// FailedTests is the number of HTTP, DNS, speed, VPN and ping tests that failed
+	FailedTests int `json:"failed_tests"`
+}
+
+// SpeedTestAggregate summarizes the download speeds of several successful speed tests
+type SpeedTestAggregate struct {
+	Count              int     `json:"count"`
+	MinMbps            float64 `json:"min_mbps"`
+	MaxMbps            float64 `json:"max_mbps"`
+	MeanMbps           float64 `json:"mean_mbps"`
+	MedianMbps         float64 `json:"median_mbps"`
+	P95Mbps            float64 `json:"p95_mbps"`
+	StdDevMbps         float64 `json:"stddev_mbps"`
+	TotalBytesReceived int64   `json:"total_bytes_received"`
 }
 
 // HTTPTest represents the result of an HTTP test
 type HTTPTest struct {
-	URL            string `json:"url"`
-	Status         string `json:"status"`
-	Proto          string `json:"proto,omitempty"`
-	TLSVersion     string `json:"tls_version,omitempty"`
-	CipherSuite    string `json:"cipher_suite,omitempty"`
-	ServerName     string `json:"server_name,omitempty"`
-	ResponseLength int    `json:"response_length,omitempty"`
-	Error          string `json:"error,omitempty"`
+	URL             string `json:"url"`
+	Status          string `json:"status"`
+	Proto           string `json:"proto,omitempty"`
+	TLSVersion      string `json:"tls_version,omitempty"`
+	CipherSuite     string `json:"cipher_suite,omitempty"`
+	CipherSuiteName string `json:"cipher_suite_name,omitempty"`
+	ServerName      string `json:"server_name,omitempty"`
+	ServerIP        string `json:"server_ip,omitempty"`
+	SourceIP        string `json:"source_ip,omitempty"`
+	FinalURL        string `json:"final_url,omitempty"`
+	ResponseLength  int    `json:"response_length,omitempty"`
+	ContentLength   int64  `json:"content_length,omitempty"`
+	BodyTruncated   bool   `json:"body_truncated,omitempty"`
+	Error           string `json:"error,omitempty"`
+	ErrorCode       int    `json:"error_code,omitempty"`
+
+	// DNSResolutionTime is how long resolving the host took (0 when no lookup was needed)
+	DNSResolutionTime time.Duration `json:"dns_resolution_time_ns,omitempty"`
+
+	// Latency is the time from requesting a connection to the first response byte
+	Latency time.Duration `json:"latency_ns,omitempty"`
+
+	// TotalTime is the full duration of the test including reading the body
+	TotalTime time.Duration `json:"total_time_ns,omitempty"`
+
+	// RateLimitDetected is set when the server answered 429 Too Many Requests
+	RateLimitDetected bool `json:"rate_limit_detected,omitempty"`
+
+	// ResponseHeaders holds the first value of each response header when capture is enabled
+	ResponseHeaders map[string]string `json:"response_headers,omitempty"`
+
+	// ServerTiming holds the metrics the server reported in its Server-Timing header
+	ServerTiming *ServerTimingInfo `json:"server_timing,omitempty"`
+
+	// AttemptCount is the number of requests the test sent
+	AttemptCount int `json:"attempt_count,omitempty"`
+
+	// Cached is set when a conditional revalidation request was answered with 304 Not Modified
+	Cached bool `json:"cached,omitempty"`
+
+	// CertExpiry is when the server's leaf certificate expires
+	CertExpiry time.Time `json:"cert_expiry,omitempty"`
+
+	// TLSCertWarning is set when the certificate expired or expires within the warning window
+	TLSCertWarning string `json:"tls_cert_warning,omitempty"`
+
+	// CompressedSize and UncompressedSize are the body size on the wire and after decoding
+	// when the response kept its Content-Encoding, which needs MeasureCompression for gzip
+	CompressedSize   int64 `json:"compressed_size,omitempty"`
+	UncompressedSize int64 `json:"uncompressed_size,omitempty"`
+
+	// CompressionSavings is the number of bytes compression saved
+	CompressionSavings int64 `json:"compression_savings,omitempty"`
+
+	// CompressionRatio is UncompressedSize divided by CompressedSize
+	CompressionRatio float64 `json:"compression_ratio,omitempty"`
+
+	// HTTP2PushCount is the number of resources the server pushed when push detection is enabled
+	HTTP2PushCount int `json:"http2_push_count,omitempty"`
+
+	// ResponseBodyHash is the hex SHA-256 of the decoded response body when body hashing is enabled
+	ResponseBodyHash string `json:"response_body_hash,omitempty"`
+
+	// RedirectTimings holds how long each request of the redirect chain took, until its response
+	// arrived, when HTTPFollowRedirects is enabled. The last entry times the final request.
+	RedirectTimings []time.Duration `json:"redirect_timings_ns,omitempty"`
+
+	// Truncated is set when reading the body stopped at the configured maximum body size.
+	// The length and hash fields then describe only the bytes read.
+	Truncated bool `json:"truncated,omitempty"`
+
+	// RequestTrace is the timing breakdown of the request when tracing is enabled
+	RequestTrace *TraceDetail `json:"request_trace,omitempty"`
+}
+
+// TraceDetail holds the httptrace events of an HTTP request and the phase durations derived from them.
+// Events that did not happen, such as DNS and connect events on a reused connection, are zero.
+// With redirects the events describe the last request.
+type TraceDetail struct {
+	DNSStart     time.Time `json:"dns_start,omitempty"`
+	DNSDone      time.Time `json:"dns_done,omitempty"`
+	ConnectStart time.Time `json:"connect_start,omitempty"`
+	ConnectDone  time.Time `json:"connect_done,omitempty"`
+	TLSStart     time.Time `json:"tls_start,omitempty"`
+	TLSDone      time.Time `json:"tls_done,omitempty"`
+	WroteHeaders time.Time `json:"wrote_headers,omitempty"`
+	GotFirstByte time.Time `json:"got_first_byte,omitempty"`
+
+	DNSTime     time.Duration `json:"dns_time_ns,omitempty"`
+	ConnectTime time.Duration `json:"connect_time_ns,omitempty"`
+	TLSTime     time.Duration `json:"tls_time_ns,omitempty"`
+
+	// ServerProcessTime runs from writing the request headers to the first response byte
+	ServerProcessTime time.Duration `json:"server_process_time_ns,omitempty"`
+
+	// ContentTransferTime runs from the first response byte to the end of the body
+	ContentTransferTime time.Duration `json:"content_transfer_time_ns,omitempty"`
+}
+
+// ServerTimingInfo holds the metrics of a Server-Timing response header
+type ServerTimingInfo struct {
+	Metrics []ServerTimingMetric `json:"metrics"`
+}
+
+// ServerTimingMetric is a single Server-Timing metric such as "db;dur=53;desc=\"Database\""
+type ServerTimingMetric struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description,omitempty"`
+	Duration    time.Duration `json:"duration_ns,omitempty"`
 }
 
 // SpeedTest represents the result of a speed test
 type SpeedTest struct {
 	URL           string        `json:"url"`
 	DownloadMbps  float64       `json:"download_mbps"`
-	ElapsedTime   time.Duration `json:"elapsed_time"`
+	ElapsedTime   time.Duration `json:"elapsed_time"` // Deprecated: nanoseconds in JSON, use ElapsedTimeMS
+	ElapsedTimeMS int64         `json:"elapsed_time_ms"`
 	BytesReceived int           `json:"bytes_received"`
 	Error         string        `json:"error,omitempty"`
+	ErrorCode     int           `json:"error_code,omitempty"`
+
+	// ConnectionSetupTime is the time before the first response byte: DNS, connect, TLS and server wait
+	ConnectionSetupTime time.Duration `json:"connection_setup_time,omitempty"`
+
+	// DownloadDuration is the body transfer time that DownloadMbps is based on
+	DownloadDuration time.Duration `json:"download_duration,omitempty"`
+
+	// WarmupBytesDiscarded is how many of the received bytes arrived during the warmup and were left out of DownloadMbps
+	WarmupBytesDiscarded int64 `json:"warmup_bytes_discarded,omitempty"`
+
+	// PerceivedQuality labels DownloadMbps, see RateDownloadQuality
+	PerceivedQuality string `json:"perceived_quality,omitempty"`
+
+	// Protocol is the HTTP version of the download: "HTTP/1.1", "HTTP/2.0" or "HTTP/3.0"
+	Protocol string `json:"protocol,omitempty"`
 }
 
 // VPNTest represents the result of a VPN detection test
 type VPNTest struct {
-	Status string `json:"status"`
-	Error  string `json:"error,omitempty"`
+	Status     string `json:"status"`
+	ExternalIP string `json:"external_ip,omitempty"`
+	Error      string `json:"error,omitempty"`
+	ErrorCode  int    `json:"error_code,omitempty"`
+
+	// ExternalIPv6 and IPv6Status describe the IPv6 exit path; both are empty without IPv6
+	ExternalIPv6 string `json:"external_ipv6,omitempty"`
+	IPv6Status   string `json:"ipv6_status,omitempty"`
+
+	// Geo is the location of the external IP when geolocation is enabled
+	Geo *GeoTest `json:"geo,omitempty"`
 }
 
 // PingTest represents the result of a ping test
@@ -44,7 +213,467 @@ type PingTest struct {
 	Transmitted int     `json:"transmitted_packets,omitempty"`
 	Received    int     `json:"received_packets,omitempty"`
 	Loss        float64 `json:"loss_packets,omitempty"`
+	TTL         int     `json:"ttl,omitempty"`
+	MinRTTMS    float64 `json:"min_rtt_ms,omitempty"`
+	AvgRTTMS    float64 `json:"avg_rtt_ms,omitempty"`
+	MaxRTTMS    float64 `json:"max_rtt_ms,omitempty"`
+	StdDevRTTMS float64 `json:"stddev_rtt_ms,omitempty"`
 	Error       string  `json:"error,omitempty"`
+	ErrorCode   int     `json:"error_code,omitempty"`
+
+	// Statistics holds the remaining ping statistics once the ping finished
+	Statistics *PingStatistics `json:"statistics,omitempty"`
+
+	// PerceivedQuality labels the average RTT and loss, see RatePingQuality
+	PerceivedQuality string `json:"perceived_quality,omitempty"`
+}
+
+// PingStatistics holds the ping statistics not already reported at the top level of PingTest
+type PingStatistics struct {
+	// Duplicate is the number of duplicate replies received
+	Duplicate int `json:"duplicate"`
+
+	// DuplicateLoss is the percentage of sent packets that were answered more than once
+	DuplicateLoss float64 `json:"duplicate_loss"`
+
+	// IPAddr is the address that was pinged
+	IPAddr string `json:"ip_addr,omitempty"`
+
+	// RTTsMS holds the round-trip time of every reply in milliseconds
+	RTTsMS []float64 `json:"rtts_ms,omitempty"`
+}
+
+// DNSTest represents the result of a DNS resolution test
+type DNSTest struct {
+	Domain         string        `json:"domain"`
+	Resolver       string        `json:"resolver"`
+	IPs            []string      `json:"ips,omitempty"`
+	ResolutionTime time.Duration `json:"resolution_time"`
+	Error          string        `json:"error,omitempty"`
+	ErrorCode      int           `json:"error_code,omitempty"`
+}
+
+// CertPinTest represents the result of a certificate pinning check
+type CertPinTest struct {
+	URL              string   `json:"url"`
+	PinMatched       bool     `json:"pin_matched"`
+	MatchedPin       string   `json:"matched_pin,omitempty"`
+	CertFingerprints []string `json:"cert_fingerprints,omitempty"`
+	Error            string   `json:"error,omitempty"`
+}
+
+// DNSCachePoisonTest represents the result of comparing DNS answers from two resolvers
+type DNSCachePoisonTest struct {
+	Domain     string   `json:"domain"`
+	Resolver1  string   `json:"resolver1"`
+	Resolver2  string   `json:"resolver2"`
+	IPs1       []string `json:"ips1,omitempty"`
+	IPs2       []string `json:"ips2,omitempty"`
+	Consistent bool     `json:"consistent"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// ThrottleTest represents the result of a bandwidth throttling check
+type ThrottleTest struct {
+	URL              string  `json:"url"`
+	Run1Mbps         float64 `json:"run1_mbps"`
+	Run2Mbps         float64 `json:"run2_mbps"`
+	DeltaPct         float64 `json:"delta_pct"`
+	ThrottleDetected bool    `json:"throttle_detected"`
+	Error            string  `json:"error,omitempty"`
+}
+
+// DoHTest represents the result of a DNS-over-HTTPS resolution
+type DoHTest struct {
+	Domain      string        `json:"domain"`
+	DoHURL      string        `json:"doh_url"`
+	ResolvedIPs []string      `json:"resolved_ips,omitempty"`
+	RTT         time.Duration `json:"rtt"`
+	StatusCode  int           `json:"status_code,omitempty"`
+	Error       string        `json:"error,omitempty"`
+}
+
+// WHOISTest represents the result of a WHOIS lookup
+type WHOISTest struct {
+	Domain          string    `json:"domain"`
+	RawResponse     string    `json:"raw_response,omitempty"`
+	Registrar       string    `json:"registrar,omitempty"`
+	CreationDate    time.Time `json:"creation_date,omitempty"`
+	ExpiryDate      time.Time `json:"expiry_date,omitempty"`
+	DaysUntilExpiry int       `json:"days_until_expiry,omitempty"`
+	Status          []string  `json:"status,omitempty"`
+	Error           string    `json:"error,omitempty"`
+}
+
+// SubdomainResult represents a subdomain that resolved during enumeration
+type SubdomainResult struct {
+	Subdomain string   `json:"subdomain"`
+	IPs       []string `json:"ips"`
+}
+
+// SubdomainEnumTest represents the result of a subdomain enumeration
+type SubdomainEnumTest struct {
+	Domain          string            `json:"domain"`
+	TestedCount     int               `json:"tested_count"`
+	FoundSubdomains []SubdomainResult `json:"found_subdomains,omitempty"`
+	ScanDuration    time.Duration     `json:"scan_duration"`
+	Error           string            `json:"error,omitempty"`
+}
+
+// PipeliningTest represents the result of an HTTP/1.1 pipelining check
+type PipeliningTest struct {
+	URL             string        `json:"url"`
+	Supported       bool          `json:"supported"`
+	Response1Status string        `json:"response1_status,omitempty"`
+	Response2Status string        `json:"response2_status,omitempty"`
+	TotalTime       time.Duration `json:"total_time"`
+	Error           string        `json:"error,omitempty"`
+}
+
+// DoTTest represents the result of a DNS-over-TLS resolution
+type DoTTest struct {
+	Domain        string        `json:"domain"`
+	DoTServer     string        `json:"dot_server"`
+	ResolvedIPs   []string      `json:"resolved_ips,omitempty"`
+	HandshakeTime time.Duration `json:"handshake_time"`
+	QueryTime     time.Duration `json:"query_time"`
+	TLSVersion    string        `json:"tls_version,omitempty"`
+	Error         string        `json:"error,omitempty"`
+}
+
+// PortScanTest represents the result of a TCP port scan
+type PortScanTest struct {
+	Host         string         `json:"host"`
+	Results      map[int]string `json:"results"`
+	OpenPorts    []int          `json:"open_ports,omitempty"`
+	ScanDuration time.Duration  `json:"scan_duration"`
+	Error        string         `json:"error,omitempty"`
+}
+
+// BGPRouteTest represents whether a prefix is announced by the expected origin AS
+type BGPRouteTest struct {
+	Prefix           string   `json:"prefix"`
+	ExpectedASN      int      `json:"expected_asn"`
+	Announced        bool     `json:"announced"`
+	ObservingRouters []string `json:"observing_routers,omitempty"`
+	ASPath           []int    `json:"as_path,omitempty"`
+	Error            string   `json:"error,omitempty"`
+}
+
+// CAARecord represents a single DNS CAA record
+type CAARecord struct {
+	Tag      string `json:"tag"`
+	Value    string `json:"value"`
+	Critical bool   `json:"critical,omitempty"`
+}
+
+// CAATest represents the result of a CAA record lookup
+type CAATest struct {
+	Domain               string      `json:"domain"`
+	Records              []CAARecord `json:"records,omitempty"`
+	IssueAllowed         []string    `json:"issue_allowed,omitempty"`
+	IssueWildcardAllowed []string    `json:"issue_wildcard_allowed,omitempty"`
+	Error                string      `json:"error,omitempty"`
+}
+
+// ACLTest represents which ports of a target a firewall or ACL lets through
+type ACLTest struct {
+	TargetIP string `json:"target_ip"`
+	Protocol string `json:"protocol"`
+
+	// Allowed holds every tested port, true when traffic to it was permitted
+	Allowed      map[int]bool  `json:"allowed"`
+	Denied       []int         `json:"denied,omitempty"`
+	Timedout     []int         `json:"timedout,omitempty"`
+	ScanDuration time.Duration `json:"scan_duration"`
+	Error        string        `json:"error,omitempty"`
+}
+
+// GeoTest represents the geolocation of an IP address
+type GeoTest struct {
+	IP       string `json:"ip"`
+	City     string `json:"city,omitempty"`
+	Region   string `json:"region,omitempty"`
+	Country  string `json:"country,omitempty"`
+	Org      string `json:"org,omitempty"`
+	Timezone string `json:"timezone,omitempty"`
+	Loc      string `json:"loc,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// HTTPTestStats summarizes repeated HTTP tests of one URL.
+// Response times cover the successful runs only.
+type HTTPTestStats struct {
+	URL             string        `json:"url"`
+	Runs            int           `json:"runs"`
+	ErrorCount      int           `json:"error_count"`
+	MinResponseTime time.Duration `json:"min_response_time_ns"`
+	MaxResponseTime time.Duration `json:"max_response_time_ns"`
+	AvgResponseTime time.Duration `json:"avg_response_time_ns"`
+}
+
+// SpeedTestStats summarizes repeated speed tests of one URL.
+// Speeds cover the successful runs only.
+type SpeedTestStats struct {
+	URL        string  `json:"url"`
+	Runs       int     `json:"runs"`
+	ErrorCount int     `json:"error_count"`
+	MinMbps    float64 `json:"min_mbps"`
+	MaxMbps    float64 `json:"max_mbps"`
+	AvgMbps    float64 `json:"avg_mbps"`
+}
+
+// SMTPTLSTest represents the result of a STARTTLS check against a mail server
+type SMTPTLSTest struct {
+	Host            string    `json:"host"`
+	Port            int       `json:"port"`
+	Reachable       bool      `json:"reachable"`
+	STARTTLSOffered bool      `json:"starttls_offered"`
+	TLSVersion      string    `json:"tls_version,omitempty"`
+	CertExpiry      time.Time `json:"cert_expiry,omitempty"`
+	Error           string    `json:"error,omitempty"`
+}
+
+// NetBIOSTest represents the result of a NetBIOS node status query
+type NetBIOSTest struct {
+	Host       string        `json:"host"`
+	Names      []string      `json:"names,omitempty"`
+	Workgroup  string        `json:"workgroup,omitempty"`
+	MACAddress string        `json:"mac_address,omitempty"`
+	RTT        time.Duration `json:"rtt"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// SSHBannerTest represents the identification string of an SSH server
+type SSHBannerTest struct {
+	Host           string        `json:"host"`
+	Port           int           `json:"port"`
+	Banner         string        `json:"banner,omitempty"`
+	SSHVersion     string        `json:"ssh_version,omitempty"`
+	ServerSoftware string        `json:"server_software,omitempty"`
+	RTT            time.Duration `json:"rtt"`
+	Error          string        `json:"error,omitempty"`
+}
+
+// KeepaliveTest represents whether an idle TCP connection with keepalive survived
+type KeepaliveTest struct {
+	Host               string        `json:"host"`
+	Port               int           `json:"port"`
+	KeepaliveEnabled   bool          `json:"keepalive_enabled"`
+	IdleDuration       time.Duration `json:"idle_duration"`
+	ConnectionSurvived bool          `json:"connection_survived"`
+	Error              string        `json:"error,omitempty"`
+}
+
+// HTTPMethodsTest represents the HTTP methods a server allows
+type HTTPMethodsTest struct {
+	URL            string   `json:"url"`
+	AllowedMethods []string `json:"allowed_methods,omitempty"`
+	ViaOptions     bool     `json:"via_options"`
+	TraceEnabled   bool     `json:"trace_enabled"`
+	Error          string   `json:"error,omitempty"`
+}
+
+// HTTPAuthTest represents the responses of an endpoint requested with and without credentials
+type HTTPAuthTest struct {
+	URL            string `json:"url"`
+	UnauthStatus   string `json:"unauth_status,omitempty"`
+	AuthStatus     string `json:"auth_status,omitempty"`
+	AuthRequired   bool   `json:"auth_required"`
+	AuthSuccessful bool   `json:"auth_successful"`
+	Error          string `json:"error,omitempty"`
+}
+
+// IXPTest represents the reachability of an Internet Exchange Point route server's BGP port
+type IXPTest struct {
+	IXP         string        `json:"ixp"`
+	RouteServer string        `json:"route_server,omitempty"`
+	Reachable   bool          `json:"reachable"`
+	DialTime    time.Duration `json:"dial_time"`
+	Banner      string        `json:"banner,omitempty"`
+	Error       string        `json:"error,omitempty"`
+}
+
+// LatencyVarianceTest represents the latency distribution of repeated requests to a URL
+type LatencyVarianceTest struct {
+	URL         string        `json:"url"`
+	Samples     int           `json:"samples"`
+	MeanLatency time.Duration `json:"mean_latency"`
+	StdDev      time.Duration `json:"std_dev"`
+	P95         time.Duration `json:"p95"`
+	CV          float64       `json:"cv"`
+	Error       string        `json:"error,omitempty"`
+
+	// LatencySamples holds every measured latency when sample recording is enabled
+	LatencySamples []time.Duration `json:"latency_samples,omitempty"`
+}
+
+// MXReachabilityTest represents whether the mail servers of a domain accept SMTP connections
+type MXReachabilityTest struct {
+	Domain       string     `json:"domain"`
+	MXRecords    []MXResult `json:"mx_records,omitempty"`
+	AllReachable bool       `json:"all_reachable"`
+	Error        string     `json:"error,omitempty"`
+}
+
+// MXResult represents the reachability of a single mail server
+type MXResult struct {
+	Hostname  string `json:"hostname"`
+	Priority  int    `json:"priority"`
+	Reachable bool   `json:"reachable"`
+	Banner    string `json:"banner,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// RPKITest represents the RPKI route origin validity of a prefix
+type RPKITest struct {
+	Prefix   string `json:"prefix"`
+	ASN      int    `json:"asn,omitempty"`
+	Validity string `json:"validity,omitempty"`
+	VRPCount int    `json:"vrp_count"`
+	Error    string `json:"error,omitempty"`
+}
+
+// LatencyProfile is a baseline of network latency in milliseconds
+type LatencyProfile struct {
+	// LocalLoop is the fastest connection to any target, the best case path to the internet
+	LocalLoop float64 `json:"local_loop"`
+
+	// RegionalAvg is the average latency to nearby anycast services
+	RegionalAvg float64 `json:"regional_avg"`
+
+	// GlobalAvg is the average latency to hosts on different continents
+	GlobalAvg float64 `json:"global_avg"`
+
+	// EstimatedTier is the access technology suggested by the regional latency (e.g., "fiber")
+	EstimatedTier string `json:"estimated_tier"`
+
+	Error string `json:"error,omitempty"`
+}
+
+// HTTPSRedirectTest represents whether a plain HTTP URL redirects to HTTPS
+type HTTPSRedirectTest struct {
+	OriginalURL    string   `json:"original_url"`
+	FinalURL       string   `json:"final_url,omitempty"`
+	RedirectsHTTPS bool     `json:"redirects_https"`
+	HSTSPresent    bool     `json:"hsts_present"`
+	RedirectCount  int      `json:"redirect_count"`
+	RedirectChain  []string `json:"redirect_chain,omitempty"`
+	Error          string   `json:"error,omitempty"`
+}
+
+// TorExitTest represents whether an IP address is a Tor exit node
+type TorExitTest struct {
+	IP          string        `json:"ip"`
+	CheckedPort int           `json:"checked_port"`
+	IsTorExit   bool          `json:"is_tor_exit"`
+	QueryRTT    time.Duration `json:"query_rtt"`
+	Error       string        `json:"error,omitempty"`
+}
+
+// AnyCastTest represents the answers several public resolvers give for a domain
+type AnyCastTest struct {
+	Domain          string              `json:"domain"`
+	ResolverResults map[string][]string `json:"resolver_results,omitempty"`
+	AnyCastDetected bool                `json:"anycast_detected"`
+	UniqueIPs       []string            `json:"unique_ips,omitempty"`
+	Error           string              `json:"error,omitempty"`
+}
+
+// CertChainTest represents the certificate chain presented by an HTTPS server
+type CertChainTest struct {
+	URL           string     `json:"url"`
+	ChainLength   int        `json:"chain_length"`
+	ChainComplete bool       `json:"chain_complete"`
+	AnyExpired    bool       `json:"any_expired"`
+	Certificates  []CertInfo `json:"certificates,omitempty"`
+	Error         string     `json:"error,omitempty"`
+}
+
+// CertInfo describes a single certificate of a chain
+type CertInfo struct {
+	Subject   string    `json:"subject"`
+	Issuer    string    `json:"issuer"`
+	NotBefore time.Time `json:"not_before"`
+	NotAfter  time.Time `json:"not_after"`
+	IsCA      bool      `json:"is_ca"`
+	Expired   bool      `json:"expired"`
+}
+
+// IPv6ConnTest represents which kinds of IPv6 traffic reach the internet
+type IPv6ConnTest struct {
+	HTTPReachable bool   `json:"http_reachable"`
+	ICMPReachable bool   `json:"icmp_reachable"`
+	DNSReachable  bool   `json:"dns_reachable"`
+	ExternalIPv6  string `json:"external_ipv6,omitempty"`
+
+	// ConnectivityScore is the number of reachable checks, from 0 to 3
+	ConnectivityScore int `json:"connectivity_score"`
+
+	Error string `json:"error,omitempty"`
+}
+
+// DNSTTLTest represents the TTLs a domain's authoritative nameserver hands out
+type DNSTTLTest struct {
+	Domain          string `json:"domain"`
+	AuthoritativeNS string `json:"authoritative_ns"`
+
+	// SOATTL is the TTL of the zone's SOA record and RecordTTL the lowest TTL of the A answer
+	SOATTL    uint32 `json:"soa_ttl"`
+	RecordTTL uint32 `json:"record_ttl"`
+
+	// NegativeTTL is how long resolvers cache a missing record (RFC 2308)
+	NegativeTTL uint32 `json:"negative_ttl"`
+
+	QueryTime time.Duration `json:"query_time"`
+
+	// LowTTLWarning is set when RecordTTL is below 60 seconds
+	LowTTLWarning bool   `json:"low_ttl_warning"`
+	Error         string `json:"error,omitempty"`
+}
+
+// CompressionSupportTest represents which content encodings a server compresses responses with
+type CompressionSupportTest struct {
+	URL            string `json:"url"`
+	SupportsGzip   bool   `json:"supports_gzip"`
+	SupportsBrotli bool   `json:"supports_brotli"`
+	SupportsZstd   bool   `json:"supports_zstd"`
+
+	// GzipRatio and BrotliRatio are the uncompressed size divided by the compressed size
+	GzipRatio   float64 `json:"gzip_ratio,omitempty"`
+	BrotliRatio float64 `json:"brotli_ratio,omitempty"`
+
+	Error string `json:"error,omitempty"`
+}
+
+// BufferbloatTest represents how much the latency to a host grows while a download saturates the link
+type BufferbloatTest struct {
+	Host string `json:"host"`
+
+	// BaselineRTT and LoadedRTT are median TCP connect times when idle and during the download
+	BaselineRTT time.Duration `json:"baseline_rtt"`
+	LoadedRTT   time.Duration `json:"loaded_rtt"`
+	Increase    time.Duration `json:"increase"`
+
+	// BufferbloatSeverity is "none", "mild" or "severe", see RateBufferbloat
+	BufferbloatSeverity string `json:"bufferbloat_severity"`
+
+	Error string `json:"error,omitempty"`
+}
+
+// MultiHomedTest represents the download speed and latency through each network interface
+type MultiHomedTest struct {
+	Interfaces []InterfaceResult `json:"interfaces"`
+	Error      string            `json:"error,omitempty"`
+}
+
+// InterfaceResult is the result of the tests bound to one interface's primary IP
+type InterfaceResult struct {
+	Name         string  `json:"name"`
+	IP           string  `json:"ip"`
+	DownloadMbps float64 `json:"download_mbps"`
+	LatencyMs    float64 `json:"latency_ms"`
+	Error        string  `json:"error,omitempty"`
 }
 
 // Tests is kept for backward compatibility with existing data.json
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
+)
+
+// tableURLWidth is the longest URL shown in a table; longer ones are truncated with an ellipsis
+const tableURLWidth = 50
+
+// NewHTTPTestTable formats HTTP tests as a fixed-width text table with one row per test
+func NewHTTPTestTable(tests []HTTPTest) string {
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "#\tURL\tStatus\tProto\tTLSVersion\tRespLen\tLatency\tError")
+	for i, t := range tests {
+		tlsVersion := t.TLSVersion
+		if v, err := strconv.ParseUint(t.TLSVersion, 10, 16); err == nil {
+			tlsVersion = TLSVersionName(uint16(v))
+		}
+
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			i+1, truncate(t.URL, tableURLWidth), t.Status, t.Proto, tlsVersion,
+			t.ResponseLength, t.Latency.Round(time.Microsecond), t.Error)
+	}
+
+	tw.Flush()
+	return buf.String()
+}
+
+// NewProtocolComparisonTable formats speed tests of the same URLs run over different HTTP versions.
+// requested holds the HTTP version each test was forced to, in the same order as tests.
+func NewProtocolComparisonTable(tests []SpeedTest, requested []string) string {
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "URL\tRequested\tProtocol\tMB/s\tBytes\tError")
+	for i, t := range tests {
+		version := ""
+		if i < len(requested) {
+			version = "HTTP/" + requested[i]
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%.2f\t%d\t%s\n",
+			truncate(t.URL, tableURLWidth), version, t.Protocol, t.DownloadMbps/8, t.BytesReceived, t.Error)
+	}
+
+	tw.Flush()
+	return buf.String()
+}
+
+// PrintSummaryTable writes a table of the HTTP tests in results to w
+func PrintSummaryTable(w io.Writer, results *TestResults) {
+	if len(results.HTTPTests) == 0 {
+		return
+	}
+	fmt.Fprint(w, NewHTTPTestTable(results.HTTPTests))
+}
+
+// truncate shortens s to at most width characters, marking the cut with an ellipsis
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:width-1]) + "…"
+}
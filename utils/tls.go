@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"crypto/tls"
+	"fmt"
+)
+
+// ParseCipherSuiteID returns the RFC name of a TLS cipher suite (e.g., "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256").
+// Unknown IDs are returned as a hexadecimal string such as "0x1301".
+func ParseCipherSuiteID(id uint16) string {
+	for _, suite := range tls.CipherSuites() {
+		if suite.ID == id {
+			return suite.Name
+		}
+	}
+
+	for _, suite := range tls.InsecureCipherSuites() {
+		if suite.ID == id {
+			return suite.Name
+		}
+	}
+
+	return fmt.Sprintf("0x%04X", id)
+}
+
+// ParseTLSVersion converts a version string ("1.0", "1.1", "1.2" or "1.3") into its tls.Version* constant
+func ParseTLSVersion(version string) (uint16, error) {
+	switch version {
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	}
+	return 0, NewValidationError("Config", ErrCodeValidation, fmt.Sprintf("unsupported TLS version %q (want 1.0, 1.1, 1.2 or 1.3)", version))
+}
+
+// TLSVersionName returns a readable name for a TLS version constant (e.g., "TLS 1.3")
+func TLSVersionName(version uint16) string {
+	switch version {
+	case tls.VersionTLS10:
+		return "TLS 1.0"
+	case tls.VersionTLS11:
+		return "TLS 1.1"
+	case tls.VersionTLS12:
+		return "TLS 1.2"
+	case tls.VersionTLS13:
+		return "TLS 1.3"
+	}
+	return fmt.Sprintf("0x%04X", version)
+}
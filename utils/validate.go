@@ -0,0 +1,60 @@
+package utils
+
+import "fmt"
+
+// Validate checks the results for internal consistency and returns the first problem found
+// as a ValidationError. It checks that the timestamp is set, that tests identify their target,
+// that speeds, sizes, durations and packet counts are non-negative, that packet loss is a
+// percentage, and that the VPN test does not report both a status and an error.
+func (r *TestResults) Validate() error {
+	if r == nil {
+		return newResultsValidationError("results cannot be nil")
+	}
+
+	if r.Timestamp.IsZero() {
+		return newResultsValidationError("timestamp is not set")
+	}
+
+	for i, t := range r.HTTPTests {
+		switch {
+		case t.URL == "":
+			return newResultsValidationError(fmt.Sprintf("http_tests[%d]: url is empty", i))
+		case t.ResponseLength < 0:
+			return newResultsValidationError(fmt.Sprintf("http_tests[%d]: response_length is negative", i))
+		case t.Latency < 0 || t.TotalTime < 0:
+			return newResultsValidationError(fmt.Sprintf("http_tests[%d]: timing is negative", i))
+		}
+	}
+
+	for i, t := range r.SpeedTests {
+		switch {
+		case t.URL == "":
+			return newResultsValidationError(fmt.Sprintf("speed_tests[%d]: url is empty", i))
+		case t.DownloadMbps < 0:
+			return newResultsValidationError(fmt.Sprintf("speed_tests[%d]: download_mbps is negative", i))
+		case t.BytesReceived < 0:
+			return newResultsValidationError(fmt.Sprintf("speed_tests[%d]: bytes_received is negative", i))
+		case t.ElapsedTime < 0 || t.ElapsedTimeMS < 0:
+			return newResultsValidationError(fmt.Sprintf("speed_tests[%d]: elapsed time is negative", i))
+		}
+	}
+
+	p := r.PingTest
+	switch {
+	case p.Transmitted < 0 || p.Received < 0:
+		return newResultsValidationError("ping_test: packet counts are negative")
+	case p.Loss < 0 || p.Loss > 100:
+		return newResultsValidationError(fmt.Sprintf("ping_test: loss_packets %.2f is not a percentage", p.Loss))
+	}
+
+	if r.VPNTest.Status != "" && r.VPNTest.Error != "" {
+		return newResultsValidationError("vpn_test: both status and error are set")
+	}
+
+	return nil
+}
+
+// newResultsValidationError creates a ValidationError for inconsistent results
+func newResultsValidationError(message string) *ValidationError {
+	return NewValidationError("Results", ErrCodeValidation, message)
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// LoadWordlist reads one entry per line from a file, skipping blank lines and "#" comments
+func LoadWordlist(filePath string) ([]string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, NewValidationError("Wordlist", ErrCodeValidation, "cannot open wordlist: "+err.Error())
+	}
+	defer f.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, NewParseError("Wordlist", ErrCodeParse, "failed to read wordlist", err)
+	}
+
+	return words, nil
+}
@@ -0,0 +1,286 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported results formats
+const (
+	FormatJSON   = "json"
+	FormatCSV    = "csv"
+	FormatHTML   = "html"
+	FormatInflux = "influx"
+)
+
+// ResultWriter serializes test results in a specific output format
+type ResultWriter interface {
+	// WriteResults writes results to w
+	WriteResults(w io.Writer, results *TestResults) error
+
+	// Extension returns the conventional file extension of the format, including the dot
+	Extension() string
+}
+
+// NewResultWriter returns the ResultWriter for format
+func NewResultWriter(format string) (ResultWriter, error) {
+	switch strings.ToLower(format) {
+	case "", FormatJSON:
+		return jsonWriter{}, nil
+	case FormatCSV:
+		return csvWriter{}, nil
+	case FormatHTML:
+		return htmlWriter{}, nil
+	case FormatInflux:
+		return influxWriter{}, nil
+	}
+	return nil, NewValidationError("Config", ErrCodeValidation,
+		fmt.Sprintf("unsupported results format %q (want json, csv, html or influx)", format))
+}
+
+// SaveResultsFormat saves results to filePath in the given format.
+// JSON output is gzip compressed like in SaveResults when filePath ends in .gz.
+func SaveResultsFormat(results *TestResults, filePath string, format string, filePermissions os.FileMode) error {
+	return SaveResultsRotated(results, filePath, format, filePermissions, 0, 0)
+}
+
+// SaveResultsRotated is like SaveResultsFormat but first rotates the existing file when it and
+// the new results together would exceed maxSize bytes. The existing file becomes filePath.1,
+// filePath.1 becomes filePath.2 and so on, keeping at most generations old files.
+// A maxSize of 0 disables rotation.
+func SaveResultsRotated(results *TestResults, filePath string, format string, filePermissions os.FileMode, maxSize int64, generations int) error {
+	return SaveResultsWithOptions(results, filePath, SaveOptions{
+		Format:          format,
+		FilePermissions: filePermissions,
+		RotateSize:      maxSize,
+		RotateCount:     generations,
+	})
+}
+
+// SaveOptions controls how SaveResultsWithOptions writes a results file
+type SaveOptions struct {
+	// Format is the results format, see NewResultWriter
+	Format string
+
+	// FilePermissions are the permissions of a newly created file
+	FilePermissions os.FileMode
+
+	// RotateSize and RotateCount rotate the existing file like SaveResultsRotated (0 = no rotation)
+	RotateSize  int64
+	RotateCount int
+
+	// MaxFileSize is the largest file that is written, in bytes (0 = unlimited)
+	MaxFileSize int64
+
+	// TrimOnOversize drops the oldest HTTP and speed tests until the file fits MaxFileSize
+	// instead of returning an error
+	TrimOnOversize bool
+}
+
+// SaveResultsWithOptions saves results to filePath as configured by opts
+func SaveResultsWithOptions(results *TestResults, filePath string, opts SaveOptions) error {
+	if results == nil {
+		return NewValidationError("Storage", ErrCodeValidation, "results cannot be nil")
+	}
+
+	// Encode fully before touching the file so an encoding error never truncates it
+	encode := func(r *TestResults) ([]byte, error) { return encodeResults(r, filePath, opts.Format) }
+	data, err := encode(results)
+	if err != nil {
+		return err
+	}
+
+	data, err = fitResults(results, data, encode, opts.MaxFileSize, opts.TrimOnOversize)
+	if err != nil {
+		return err
+	}
+
+	resultsMutex.Lock()
+	defer resultsMutex.Unlock()
+
+	if opts.RotateSize > 0 {
+		if err := rotateResults(filePath, int64(len(data)), opts.RotateSize, opts.RotateCount); err != nil {
+			return err
+		}
+	}
+
+	if err := os.WriteFile(filePath, data, opts.FilePermissions); err != nil {
+		return NewNetworkError("Storage", ErrCodeNetwork, "failed to write results file", err)
+	}
+
+	return nil
+}
+
+// encodeResults serializes results in format, compressing JSON when filePath ends in .gz
+func encodeResults(results *TestResults, filePath string, format string) ([]byte, error) {
+	writer, err := NewResultWriter(format)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := writer.(jsonWriter); ok && isGzipPath(filePath) {
+		return CompressResults(results)
+	}
+
+	var buf bytes.Buffer
+	if err := writer.WriteResults(&buf, results); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// jsonWriter writes indented JSON
+type jsonWriter struct{}
+
+func (jsonWriter) WriteResults(w io.Writer, results *TestResults) error {
+	return WriteResultsTo(results, w)
+}
+
+func (jsonWriter) Extension() string { return ".json" }
+
+// csvWriter writes the flat CSV table produced by MarshalCSV
+type csvWriter struct{}
+
+func (csvWriter) WriteResults(w io.Writer, results *TestResults) error {
+	data, err := results.MarshalCSV()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return NewNetworkError("Storage", ErrCodeNetwork, "failed to write CSV results", err)
+	}
+	return nil
+}
+
+func (csvWriter) Extension() string { return ".csv" }
+
+// htmlWriter writes a standalone HTML report
+type htmlWriter struct{}
+
+func (htmlWriter) WriteResults(w io.Writer, results *TestResults) error {
+	if err := htmlReport.Execute(w, results); err != nil {
+		return NewParseError("Storage", ErrCodeParse, "failed to render HTML report", err)
+	}
+	return nil
+}
+
+func (htmlWriter) Extension() string { return ".html" }
+
+// htmlReport is the template used by htmlWriter
+var htmlReport = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{with .Metadata}}{{or .Title "Internet Test Report"}}{{else}}Internet Test Report{{end}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+.error { color: #b00; }
+</style>
+</head>
+<body>
+{{with .Metadata}}<h1>{{or .Title "Internet Test Report"}}</h1>
+{{if .Description}}<p>{{.Description}}</p>
+{{end}}{{else}}<h1>Internet Test Report</h1>
+{{end}}<p>Generated {{.Timestamp.Format "2006-01-02 15:04:05 MST"}}{{with .Metadata}}{{if .GeneratedBy}} by {{.GeneratedBy}} {{.Version}}{{end}}{{end}}</p>
+{{if .HTTPTests}}
+<h2>HTTP Tests</h2>
+<table>
+<tr><th>URL</th><th>Status</th><th>Proto</th><th>Latency</th><th>Length</th><th>Error</th></tr>
+{{range .HTTPTests}}<tr><td>{{.URL}}</td><td>{{.Status}}</td><td>{{.Proto}}</td><td>{{.Latency}}</td><td>{{.ResponseLength}}</td><td class="error">{{.Error}}</td></tr>
+{{end}}</table>
+{{end}}
+{{if .SpeedTests}}
+<h2>Speed Tests</h2>
+<table>
+<tr><th>URL</th><th>Download (Mbps)</th><th>Elapsed</th><th>Bytes</th><th>Error</th></tr>
+{{range .SpeedTests}}<tr><td>{{.URL}}</td><td>{{printf "%.2f" .DownloadMbps}}</td><td>{{.ElapsedTime}}</td><td>{{.BytesReceived}}</td><td class="error">{{.Error}}</td></tr>
+{{end}}</table>
+{{end}}
+{{if .DNSTests}}
+<h2>DNS Tests</h2>
+<table>
+<tr><th>Domain</th><th>IPs</th><th>Resolution Time</th><th>Error</th></tr>
+{{range .DNSTests}}<tr><td>{{.Domain}}</td><td>{{range .IPs}}{{.}} {{end}}</td><td>{{.ResolutionTime}}</td><td class="error">{{.Error}}</td></tr>
+{{end}}</table>
+{{end}}
+{{with .PingTest}}{{if or .URL .Error}}
+<h2>Ping Test</h2>
+<table>
+<tr><th>Host</th><th>Transmitted</th><th>Received</th><th>Loss</th><th>Avg RTT (ms)</th><th>Error</th></tr>
+<tr><td>{{.URL}}</td><td>{{.Transmitted}}</td><td>{{.Received}}</td><td>{{printf "%.1f" .Loss}}%</td><td>{{printf "%.2f" .AvgRTTMS}}</td><td class="error">{{.Error}}</td></tr>
+</table>
+{{end}}{{end}}
+{{with .VPNTest}}{{if or .Status .Error}}
+<h2>VPN Test</h2>
+<p>{{.Status}} <span class="error">{{.Error}}</span></p>
+{{end}}{{end}}
+</body>
+</html>
+`))
+
+// influxWriter writes InfluxDB line protocol, one line per test
+type influxWriter struct{}
+
+func (influxWriter) WriteResults(w io.Writer, results *TestResults) error {
+	if results.Timestamp.IsZero() {
+		results.Timestamp = time.Now()
+	}
+
+	var buf bytes.Buffer
+	ts := results.Timestamp.UnixNano()
+
+	for _, t := range results.HTTPTests {
+		fmt.Fprintf(&buf, "http_test,url=%s success=%t,response_length=%di,latency_ns=%di,status=%s,error=%s %d\n",
+			influxTag(t.URL), t.Error == "", t.ResponseLength, int64(t.Latency), influxString(t.Status), influxString(t.Error), ts)
+	}
+
+	for _, t := range results.SpeedTests {
+		fmt.Fprintf(&buf, "speed_test,url=%s success=%t,download_mbps=%s,bytes_received=%di,elapsed_ms=%di,error=%s %d\n",
+			influxTag(t.URL), t.Error == "", strconv.FormatFloat(t.DownloadMbps, 'f', -1, 64), t.BytesReceived, t.ElapsedTimeMS, influxString(t.Error), ts)
+	}
+
+	for _, t := range results.DNSTests {
+		fmt.Fprintf(&buf, "dns_test,domain=%s success=%t,resolution_ns=%di,error=%s %d\n",
+			influxTag(t.Domain), t.Error == "", int64(t.ResolutionTime), influxString(t.Error), ts)
+	}
+
+	if p := results.PingTest; p.URL != "" {
+		fmt.Fprintf(&buf, "ping_test,url=%s transmitted=%di,received=%di,loss=%s,avg_rtt_ms=%s,error=%s %d\n",
+			influxTag(p.URL), p.Transmitted, p.Received, strconv.FormatFloat(p.Loss, 'f', -1, 64),
+			strconv.FormatFloat(p.AvgRTTMS, 'f', -1, 64), influxString(p.Error), ts)
+	}
+
+	if v := results.VPNTest; v.Status != "" || v.Error != "" {
+		fmt.Fprintf(&buf, "vpn_test status=%s,error=%s %d\n", influxString(v.Status), influxString(v.Error), ts)
+	}
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return NewNetworkError("Storage", ErrCodeNetwork, "failed to write line protocol results", err)
+	}
+	return nil
+}
+
+func (influxWriter) Extension() string { return ".lp" }
+
+// influxTag escapes a line protocol tag value
+var influxTagEscaper = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+
+func influxTag(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return influxTagEscaper.Replace(s)
+}
+
+// influxString quotes a line protocol string field value
+func influxString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	crand "crypto/rand"
+	"encoding/binary"
+	"log"
+	"math/rand"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ehsanghaffar/ultimate-internet-test/config"
+	"github.com/ehsanghaffar/ultimate-internet-test/utils"
+)
+
+// runWatch runs the default tests every cfg.WatchInterval until interrupted.
+// Each wait is extended by a random jitter of up to cfg.WatchJitter so that many agents
+// started with the same interval do not hit the tested servers at the same moment.
+// It returns the exit code of the run that stopped it early, or 0 once interrupted.
+func runWatch(ctx context.Context, cfg *config.Config) int {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	rng := newJitterRand()
+
+	var previous *utils.TestResults
+	for {
+		results := runAllTests(ctx, cfg)
+		if previous != nil {
+			for _, change := range utils.ChangedBodyHashes(previous, results) {
+				log.Printf("Warning: response body of %s changed (SHA-256 %s -> %s)\n", change.URL, change.Previous, change.Current)
+			}
+		}
+		previous = results
+		if code := finishRun(results, cfg); code != 0 {
+			return code
+		}
+
+		wait := cfg.WatchInterval
+		if cfg.WatchJitter > 0 {
+			jitter := time.Duration(rng.Int63n(int64(cfg.WatchJitter) + 1))
+			wait += jitter
+			debugf("Next run in %s (interval %s + jitter %s)\n", wait, cfg.WatchInterval, jitter)
+		} else {
+			debugf("Next run in %s\n", wait)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			log.Println("Watch mode stopped")
+			return 0
+		case <-timer.C:
+		}
+	}
+}
+
+// newJitterRand returns a random source seeded from crypto/rand so that agents
+// started at the same time still pick different jitters
+func newJitterRand() *rand.Rand {
+	var seed int64
+	if err := binary.Read(crand.Reader, binary.LittleEndian, &seed); err != nil {
+		seed = time.Now().UnixNano()
+	}
+	return rand.New(rand.NewSource(seed))
+}